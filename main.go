@@ -2,6 +2,7 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
@@ -55,8 +56,15 @@ func main() {
 		inputBytes = []byte(os.Args[len(os.Args)-1])
 	}
 	parser := clickhouse.NewParser(string(inputBytes))
+	if options.format {
+		parser.RetainComments()
+	}
 	stmts, err := parser.ParseStatements()
 	if err != nil {
+		var parseError *clickhouse.ParseError
+		if errors.As(err, &parseError) {
+			panic(fmt.Sprintf("parse statements error: %s", parseError.Pretty()))
+		}
 		panic(fmt.Sprintf("parse statements error: %s", err.Error()))
 	}
 	if !options.format { // print AST
@@ -0,0 +1,110 @@
+package parser
+
+import (
+	"sort"
+	"strings"
+)
+
+// AggregateFunctionName is the name of an aggregate function split into the base function and
+// the combinators, like uniq with Array and If for uniqArrayIf.
+type AggregateFunctionName struct {
+	Base        string
+	Combinators []string // in the order they appear in the name
+}
+
+// HasCombinator reports whether the combinator like State is applied to the base function.
+func (a *AggregateFunctionName) HasCombinator(combinator string) bool {
+	for _, c := range a.Combinators {
+		if c == combinator {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *AggregateFunctionName) String() string {
+	return a.Base + strings.Join(a.Combinators, "")
+}
+
+// aggregateCombinators are sorted by length in descending order, so the longest suffix like
+// MergeState is tried before State, as ClickHouse does.
+var aggregateCombinators = sortedByLengthDesc([]string{
+	"If", "Array", "Map", "SimpleState", "State", "Merge", "MergeState", "ForEach",
+	"Distinct", "OrDefault", "OrNull", "Resample", "ArgMin", "ArgMax",
+})
+
+var aggregateFunctions = NewSet(
+	"any", "anyLast", "anyHeavy", "argMin", "argMax", "avgWeighted", "topK", "topKWeighted",
+	"groupArray", "groupArrayLast", "groupUniqArray", "groupArrayInsertAt", "groupArrayMovingAvg",
+	"groupArrayMovingSum", "groupArraySample", "groupArraySorted", "groupArrayIntersect", "groupConcat",
+	"groupBitAnd", "groupBitOr", "groupBitXor", "groupBitmap", "groupBitmapAnd", "groupBitmapOr", "groupBitmapXor",
+	"sumWithOverflow", "sumMap", "sumMapWithOverflow", "minMap", "maxMap", "sumCount", "sumKahan",
+	"skewSamp", "skewPop", "kurtSamp", "kurtPop",
+	"uniq", "uniqExact", "uniqCombined", "uniqCombined64", "uniqHLL12", "uniqTheta", "uniqUpTo",
+	"quantile", "quantiles", "quantileExact", "quantilesExact", "quantileExactLow", "quantilesExactLow",
+	"quantileExactHigh", "quantilesExactHigh", "quantileExactWeighted", "quantilesExactWeighted",
+	"quantileTiming", "quantilesTiming", "quantileTimingWeighted", "quantilesTimingWeighted",
+	"quantileDeterministic", "quantilesDeterministic", "quantileTDigest", "quantilesTDigest",
+	"quantileTDigestWeighted", "quantilesTDigestWeighted", "quantileBFloat16", "quantilesBFloat16",
+	"quantileBFloat16Weighted", "quantilesBFloat16Weighted", "quantileDD", "quantilesDD",
+	"quantileGK", "quantilesGK", "quantileInterpolatedWeighted", "quantilesInterpolatedWeighted",
+	"median", "medianExact", "medianExactLow", "medianExactHigh", "medianExactWeighted", "medianTiming",
+	"medianTimingWeighted", "medianDeterministic", "medianTDigest", "medianTDigestWeighted",
+	"medianBFloat16", "medianBFloat16Weighted", "medianDD", "medianGK", "medianInterpolatedWeighted",
+	"varSampStable", "varPopStable", "stddevSampStable", "stddevPopStable", "covarSampStable",
+	"covarPopStable", "corrStable", "corrMatrix", "covarSampMatrix", "covarPopMatrix",
+	"simpleLinearRegression", "stochasticLinearRegression", "stochasticLogisticRegression",
+	"categoricalInformationValue", "entropy", "histogram", "sequenceMatch", "sequenceCount",
+	"sequenceNextNode", "windowFunnel", "retention", "maxIntersections", "maxIntersectionsPosition",
+	"meanZTest", "studentTTest", "welchTTest", "mannWhitneyUTest", "kolmogorovSmirnovTest", "rankCorr",
+	"boundingRatio", "contingency", "cramersV", "cramersVBiasCorrected", "theilsU", "deltaSum",
+	"deltaSumTimestamp", "exponentialMovingAverage", "exponentialTimeDecayedAvg",
+	"exponentialTimeDecayedCount", "exponentialTimeDecayedMax", "exponentialTimeDecayedSum",
+	"intervalLengthSum", "largestTriangleThreeBuckets", "sparkbar", "singleValueOrNull",
+	"analysisOfVariance", "approx_top_k", "approx_top_sum", "flameGraph", "nothing", "aggThrow",
+)
+
+// caseInsensitiveAggregateFunctions are in lower case
+var caseInsensitiveAggregateFunctions = NewSet(
+	"count", "sum", "avg", "min", "max", "any_value", "first_value", "last_value", "corr",
+	"covarpop", "covarsamp", "varpop", "varsamp", "stddevpop", "stddevsamp",
+	"covar_pop", "covar_samp", "var_pop", "var_samp", "stddev_pop", "stddev_samp",
+)
+
+func sortedByLengthDesc(names []string) []string {
+	sort.SliceStable(names, func(i, j int) bool {
+		return len(names[i]) > len(names[j])
+	})
+	return names
+}
+
+// IsAggregateFunction reports whether the name is a known aggregate function without combinators.
+func IsAggregateFunction(name string) bool {
+	return aggregateFunctions.Contains(name) || caseInsensitiveAggregateFunctions.Contains(strings.ToLower(name))
+}
+
+// SplitAggregateFunctionName splits the name like uniqArrayIf into the base aggregate function uniq and
+// the combinators Array and If. It returns false if the name isn't a known aggregate function followed
+// by zero or more combinators. The known function name wins over the split, so groupArray is not
+// group with the Array combinator.
+func SplitAggregateFunctionName(name string) (*AggregateFunctionName, bool) {
+	if IsAggregateFunction(name) {
+		return &AggregateFunctionName{Base: name}, true
+	}
+	for _, combinator := range aggregateCombinators {
+		if len(name) <= len(combinator) || !strings.HasSuffix(name, combinator) {
+			continue
+		}
+		if aggregate, ok := SplitAggregateFunctionName(strings.TrimSuffix(name, combinator)); ok {
+			aggregate.Combinators = append(aggregate.Combinators, combinator)
+			return aggregate, true
+		}
+	}
+	return nil, false
+}
+
+// IsGroupingFunction reports whether the name is grouping(), whose arguments must be keys of
+// GroupByExpr.Keys(), it's an aggregate-like function of the GROUP BY rather than an aggregate.
+func IsGroupingFunction(name string) bool {
+	return strings.EqualFold(name, "grouping")
+}
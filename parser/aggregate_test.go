@@ -0,0 +1,86 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitAggregateFunctionName(t *testing.T) {
+	tests := map[string]string{
+		"sum":                "sum",
+		"SUM":                "SUM",
+		"sumIf":              "sum If",
+		"uniqArrayIf":        "uniq Array If",
+		"countState":         "count State",
+		"argMaxMerge":        "argMax Merge",
+		"groupArray":         "groupArray",
+		"groupArrayIf":       "groupArray If",
+		"sumMergeState":      "sum MergeState",
+		"avgOrNull":          "avg OrNull",
+		"quantilesResample":  "quantiles Resample",
+		"uniqExactForEachIf": "uniqExact ForEach If",
+		"sumArgMax":          "sum ArgMax",
+	}
+	for name, expected := range tests {
+		aggregate, ok := SplitAggregateFunctionName(name)
+		require.True(t, ok, name)
+		got := aggregate.Base
+		for _, combinator := range aggregate.Combinators {
+			got += " " + combinator
+		}
+		require.Equal(t, expected, got, name)
+		require.Equal(t, name, aggregate.String())
+	}
+
+	for _, name := range []string{"toString", "If", "sumif", "arrayMapIf", ""} {
+		_, ok := SplitAggregateFunctionName(name)
+		require.False(t, ok, name)
+	}
+
+	aggregate, _ := SplitAggregateFunctionName("uniqStateIf")
+	require.True(t, aggregate.HasCombinator("State"))
+	require.False(t, aggregate.HasCombinator("Merge"))
+}
+
+func TestFunctionExpr_ParametersAndArguments(t *testing.T) {
+	expr, err := NewParser("quantilesIf(0.5, 0.9)(latency, ok)").ParseExpr()
+	require.NoError(t, err)
+	function := expr.(*FunctionExpr)
+	require.Equal(t, "quantilesIf(0.5, 0.9)(latency, ok)", function.String(0))
+	require.Equal(t, Pos(len("quantilesIf(0.5, 0.9)(latency, ok")), function.End())
+	require.Len(t, function.Parameters(), 2)
+	require.Len(t, function.Arguments(), 2)
+	require.Equal(t, "latency", function.Arguments()[0].String(0))
+
+	expr, err = NewParser("count(DISTINCT x)").ParseExpr()
+	require.NoError(t, err)
+	function = expr.(*FunctionExpr)
+	require.Equal(t, "count(DISTINCT x)", function.String(0))
+	require.Nil(t, function.Parameters())
+	require.Len(t, function.Arguments(), 1)
+}
+
+func TestGroupByExpr_Keys(t *testing.T) {
+	tests := map[string]string{
+		"SELECT a FROM t GROUP BY a, b":                               "a b",
+		"SELECT a FROM t GROUP BY ROLLUP(a, b)":                       "a b",
+		"SELECT a FROM t GROUP BY CUBE(a, b) WITH TOTALS":             "a b",
+		"SELECT a FROM t GROUP BY GROUPING SETS ((a, b), (a), c, ())": "a b c",
+		"SELECT a FROM t GROUP BY ALL":                                "",
+	}
+	for sql, expected := range tests {
+		query, err := NewParser(sql).ParseSelectQuery()
+		require.NoError(t, err, sql)
+		groupBy := query.GroupBy
+		var keys []string
+		for _, key := range groupBy.Keys() {
+			keys = append(keys, key.String(0))
+		}
+		require.Equal(t, expected, strings.Join(keys, " "), sql)
+	}
+
+	require.True(t, IsGroupingFunction("GROUPING"))
+	require.False(t, IsGroupingFunction("groupArray"))
+}
@@ -1,6 +1,10 @@
 package parser
 
 import (
+	"errors"
+	"math"
+	"math/big"
+	"strconv"
 	"strings"
 )
 
@@ -63,11 +67,11 @@ func (t *TernaryExpr) End() Pos {
 
 func (t *TernaryExpr) String(level int) string {
 	var builder strings.Builder
-	builder.WriteString(t.Condition.String(level))
+	builder.WriteString(formatOperand(t.Condition, precedenceTernary+1, level))
 	builder.WriteString(" ? ")
-	builder.WriteString(t.TrueExpr.String(level))
+	builder.WriteString(formatOperand(t.TrueExpr, precedenceTernary, level))
 	builder.WriteString(" : ")
-	builder.WriteString(t.FalseExpr.String(level))
+	builder.WriteString(formatOperand(t.FalseExpr, precedenceTernary, level))
 	return builder.String()
 }
 
@@ -104,23 +108,88 @@ func (p *BinaryExpr) End() Pos {
 
 func (p *BinaryExpr) String(level int) string {
 	var builder strings.Builder
-	builder.WriteString(p.LeftExpr.String(level))
-	if p.Operation != opTypeCast {
-		builder.WriteByte(' ')
+	precedence := precedenceOf(p)
+	builder.WriteString(formatOperand(p.LeftExpr, precedence, level))
+	if p.Operation == opTypeCast {
+		builder.WriteString(string(p.Operation))
+		builder.WriteString(p.RightExpr.String(level))
+		return builder.String()
+	}
+	builder.WriteByte(' ')
+	if p.HasGlobal {
+		builder.WriteString("GLOBAL ")
 	}
 	if p.HasNot {
 		builder.WriteString("NOT ")
-	} else if p.HasGlobal {
-		builder.WriteString("GLOBAL ")
 	}
 	builder.WriteString(string(p.Operation))
-	if p.Operation != opTypeCast {
-		builder.WriteByte(' ')
-	}
-	builder.WriteString(p.RightExpr.String(level))
+	builder.WriteByte(' ')
+	// the operators of the same precedence are left-associative
+	builder.WriteString(formatOperand(p.RightExpr, precedence+1, level))
 	return builder.String()
 }
 
+// precedenceOf returns the precedence of the outermost operator of the expression,
+// which tells whether the expression needs parentheses as an operand.
+func precedenceOf(expr Expr) int {
+	switch expr := expr.(type) {
+	case *AliasExpr:
+		return precedenceLowest
+	case *LambdaExpr:
+		return precedenceLambda
+	case *TernaryExpr:
+		return precedenceTernary
+	case *NotExpr:
+		return precedenceNot
+	case *IsNullExpr, *IsNotNullExpr:
+		return precedenceIs
+	case *BetweenExpr:
+		return precedenceBetween
+	case *InExpr, *QuantifiedComparisonExpr:
+		return precedenceCompare
+	case *BinaryExpr:
+		if expr.Operation == opTypeCast {
+			return precedencePostfix
+		}
+		if precedence, ok := binaryOperatorPrecedences[expr.Operation]; ok {
+			return precedence
+		}
+		return precedenceLowest
+	case *UnaryExpr:
+		// the operand of NOT extends as far as it can, so -NOT a is as weak as NOT a
+		if precedence := precedenceOf(expr.Expr); isPrefixExpr(expr.Expr) && precedence < precedenceUnary {
+			return precedence
+		}
+		return precedenceUnary
+	}
+	return precedenceHighest
+}
+
+func isPrefixExpr(expr Expr) bool {
+	switch expr.(type) {
+	case *NotExpr, *UnaryExpr:
+		return true
+	}
+	return false
+}
+
+// formatOperand formats the operand and encloses it in parentheses if its precedence is lower than minPrecedence.
+func formatOperand(operand Expr, minPrecedence int, level int) string {
+	if precedenceOf(operand) < minPrecedence {
+		return "(" + operand.String(level) + ")"
+	}
+	return operand.String(level)
+}
+
+// formatPrefixOperand formats the operand of a prefix operator, which needn't parentheses if it's also
+// a prefix expression like NOT NOT a.
+func formatPrefixOperand(operand Expr, minPrecedence int, level int) string {
+	if isPrefixExpr(operand) {
+		return operand.String(level)
+	}
+	return formatOperand(operand, minPrecedence, level)
+}
+
 func (p *BinaryExpr) Accept(visitor ASTVisitor) error {
 	visitor.enter(p)
 	defer visitor.leave(p)
@@ -133,6 +202,263 @@ func (p *BinaryExpr) Accept(visitor ASTVisitor) error {
 	return visitor.VisitBinaryExpr(p)
 }
 
+// LambdaExpr is a lambda function like x -> x * 2 or (k, v) -> v > 0,
+// the names of Params are only visible inside Body.
+type LambdaExpr struct {
+	LeftParenPos  Pos
+	RightParenPos Pos // zero if the single parameter isn't enclosed in parentheses
+	Params        []*Ident
+	ArrowPos      Pos
+	Body          Expr
+}
+
+func (l *LambdaExpr) Pos() Pos {
+	if l.RightParenPos == 0 {
+		return l.Params[0].Pos()
+	}
+	return l.LeftParenPos
+}
+
+func (l *LambdaExpr) End() Pos {
+	return l.Body.End()
+}
+
+func (l *LambdaExpr) String(level int) string {
+	var builder strings.Builder
+	hasParen := l.RightParenPos != 0 || len(l.Params) != 1
+	if hasParen {
+		builder.WriteByte('(')
+	}
+	for i, param := range l.Params {
+		if i > 0 {
+			builder.WriteString(", ")
+		}
+		builder.WriteString(param.String(level))
+	}
+	if hasParen {
+		builder.WriteByte(')')
+	}
+	builder.WriteString(" -> ")
+	builder.WriteString(l.Body.String(level))
+	return builder.String()
+}
+
+func (l *LambdaExpr) Accept(visitor ASTVisitor) error {
+	visitor.enter(l)
+	defer visitor.leave(l)
+	for _, param := range l.Params {
+		if err := param.Accept(visitor); err != nil {
+			return err
+		}
+	}
+	if err := l.Body.Accept(visitor); err != nil {
+		return err
+	}
+	return visitor.VisitLambdaExpr(l)
+}
+
+// BetweenExpr is expr [NOT] BETWEEN low AND high
+type BetweenExpr struct {
+	Expr       Expr
+	Not        bool
+	BetweenPos Pos
+	Low        Expr
+	AndPos     Pos
+	High       Expr
+}
+
+func (b *BetweenExpr) Pos() Pos {
+	return b.Expr.Pos()
+}
+
+func (b *BetweenExpr) End() Pos {
+	return b.High.End()
+}
+
+func (b *BetweenExpr) String(level int) string {
+	var builder strings.Builder
+	builder.WriteString(formatOperand(b.Expr, precedenceBetween, level))
+	if b.Not {
+		builder.WriteString(" NOT")
+	}
+	builder.WriteString(" BETWEEN ")
+	builder.WriteString(formatOperand(b.Low, precedenceBetween+1, level))
+	builder.WriteString(" AND ")
+	builder.WriteString(formatOperand(b.High, precedenceBetween+1, level))
+	return builder.String()
+}
+
+func (b *BetweenExpr) Accept(visitor ASTVisitor) error {
+	visitor.enter(b)
+	defer visitor.leave(b)
+	if err := b.Expr.Accept(visitor); err != nil {
+		return err
+	}
+	if err := b.Low.Accept(visitor); err != nil {
+		return err
+	}
+	if err := b.High.Accept(visitor); err != nil {
+		return err
+	}
+	return visitor.VisitBetweenExpr(b)
+}
+
+// SubQuery is a parenthesized query used as an expression like (SELECT max(id) FROM t)
+type SubQuery struct {
+	LeftParenPos  Pos
+	RightParenPos Pos
+	Select        *SelectQuery
+}
+
+func (s *SubQuery) Pos() Pos {
+	return s.LeftParenPos
+}
+
+func (s *SubQuery) End() Pos {
+	return s.RightParenPos
+}
+
+func (s *SubQuery) String(level int) string {
+	var builder strings.Builder
+	builder.WriteByte('(')
+	builder.WriteString(s.Select.String(level + 1))
+	builder.WriteByte(')')
+	return builder.String()
+}
+
+func (s *SubQuery) Accept(visitor ASTVisitor) error {
+	visitor.enter(s)
+	defer visitor.leave(s)
+	if err := s.Select.Accept(visitor); err != nil {
+		return err
+	}
+	return visitor.VisitSubQuery(s)
+}
+
+// InExpr is expr [GLOBAL] [NOT] IN followed by a list of values, a subquery or a table,
+// only one of List, SubQuery and Table is set.
+type InExpr struct {
+	Expr     Expr
+	Global   bool
+	Not      bool
+	InPos    Pos
+	List     Expr // the values like (1, 2), or an expression producing a tuple or an array
+	SubQuery *SubQuery
+	Table    *TableIdentifier
+}
+
+func (i *InExpr) Pos() Pos {
+	return i.Expr.Pos()
+}
+
+func (i *InExpr) End() Pos {
+	return i.right().End()
+}
+
+func (i *InExpr) right() Expr {
+	switch {
+	case i.SubQuery != nil:
+		return i.SubQuery
+	case i.Table != nil:
+		return i.Table
+	}
+	return i.List
+}
+
+func (i *InExpr) String(level int) string {
+	var builder strings.Builder
+	builder.WriteString(formatOperand(i.Expr, precedenceCompare, level))
+	if i.Global {
+		builder.WriteString(" GLOBAL")
+	}
+	if i.Not {
+		builder.WriteString(" NOT")
+	}
+	builder.WriteString(" IN ")
+	builder.WriteString(formatOperand(i.right(), precedenceCompare+1, level))
+	return builder.String()
+}
+
+func (i *InExpr) Accept(visitor ASTVisitor) error {
+	visitor.enter(i)
+	defer visitor.leave(i)
+	if err := i.Expr.Accept(visitor); err != nil {
+		return err
+	}
+	if err := i.right().Accept(visitor); err != nil {
+		return err
+	}
+	return visitor.VisitInExpr(i)
+}
+
+// ExistsExpr is EXISTS (subquery), NOT EXISTS is the NotExpr of it
+type ExistsExpr struct {
+	ExistsPos Pos
+	SubQuery  *SubQuery
+}
+
+func (e *ExistsExpr) Pos() Pos {
+	return e.ExistsPos
+}
+
+func (e *ExistsExpr) End() Pos {
+	return e.SubQuery.End()
+}
+
+func (e *ExistsExpr) String(level int) string {
+	return "EXISTS " + e.SubQuery.String(level)
+}
+
+func (e *ExistsExpr) Accept(visitor ASTVisitor) error {
+	visitor.enter(e)
+	defer visitor.leave(e)
+	if err := e.SubQuery.Accept(visitor); err != nil {
+		return err
+	}
+	return visitor.VisitExistsExpr(e)
+}
+
+// QuantifiedComparisonExpr compares the expression with the rows of a subquery like x > ALL (SELECT y FROM t)
+type QuantifiedComparisonExpr struct {
+	Expr          Expr
+	Operation     TokenKind
+	QuantifierPos Pos
+	Quantifier    string // ANY or ALL
+	SubQuery      Expr   // the subquery, or an operation on it like (SELECT 1) + 1
+}
+
+func (q *QuantifiedComparisonExpr) Pos() Pos {
+	return q.Expr.Pos()
+}
+
+func (q *QuantifiedComparisonExpr) End() Pos {
+	return q.SubQuery.End()
+}
+
+func (q *QuantifiedComparisonExpr) String(level int) string {
+	var builder strings.Builder
+	builder.WriteString(formatOperand(q.Expr, precedenceCompare, level))
+	builder.WriteByte(' ')
+	builder.WriteString(string(q.Operation))
+	builder.WriteByte(' ')
+	builder.WriteString(q.Quantifier)
+	builder.WriteByte(' ')
+	builder.WriteString(formatOperand(q.SubQuery, precedenceCompare+1, level))
+	return builder.String()
+}
+
+func (q *QuantifiedComparisonExpr) Accept(visitor ASTVisitor) error {
+	visitor.enter(q)
+	defer visitor.leave(q)
+	if err := q.Expr.Accept(visitor); err != nil {
+		return err
+	}
+	if err := q.SubQuery.Accept(visitor); err != nil {
+		return err
+	}
+	return visitor.VisitQuantifiedComparisonExpr(q)
+}
+
 type JoinTableExpr struct {
 	Table        *TableExpr
 	StatementEnd Pos
@@ -184,6 +510,8 @@ type AlterTable struct {
 	TableIdentifier *TableIdentifier
 	OnCluster       *OnClusterExpr
 	AlterExprs      []AlterTableExpr
+
+	Comments
 }
 
 func (a *AlterTable) Pos() Pos {
@@ -213,7 +541,7 @@ func (a *AlterTable) String(level int) string {
 			builder.WriteString(",")
 		}
 	}
-	return builder.String()
+	return a.formatComments(builder.String(), level)
 }
 
 func (a *AlterTable) Accept(visitor ASTVisitor) error {
@@ -595,7 +923,7 @@ func (a *AlterTableAddIndex) Accept(visitor ASTVisitor) error {
 
 type ProjectionOrderBy struct {
 	OrderByPos Pos
-	Columns    *ColumnExprList
+	Columns    *ColumnExprList // the items are *OrderByExpr
 }
 
 func (p *ProjectionOrderBy) Pos() Pos {
@@ -616,6 +944,9 @@ func (p *ProjectionOrderBy) String(level int) string {
 func (p *ProjectionOrderBy) Accept(visitor ASTVisitor) error {
 	visitor.enter(p)
 	defer visitor.leave(p)
+	if err := p.Columns.Accept(visitor); err != nil {
+		return err
+	}
 	return visitor.VisitProjectionOrderBy(p)
 }
 
@@ -1306,6 +1637,9 @@ type Ident struct {
 	QuoteType int
 	NamePos   Pos
 	NameEnd   Pos
+	// Parameter is set if the identifier is given by a query parameter like {name:Identifier},
+	// the Name is empty in this case.
+	Parameter *QueryParameterExpr `json:",omitempty"`
 }
 
 func (i *Ident) Pos() Pos {
@@ -1317,10 +1651,17 @@ func (i *Ident) End() Pos {
 }
 
 func (i *Ident) String(int) string {
+	if i.Parameter != nil {
+		return i.Parameter.String(0)
+	}
 	if i.QuoteType == BackTicks {
-		return "`" + i.Name + "`"
+		return "`" + escapeIdent(i.Name, '`') + "`"
 	} else if i.QuoteType == DoubleQuote {
-		return `"` + i.Name + `"`
+		return `"` + escapeIdent(i.Name, '"') + `"`
+	} else if i.Name != "*" && (!isBareIdent(i.Name) || i.QuoteType == 0 && keywords.Contains(strings.ToUpper(i.Name))) {
+		// the identifier is built by hand or rewritten, quote it if it can't be written bare. The parsed
+		// keywords like YEAR are kept bare, but the keyword built by hand may not be valid bare.
+		return "`" + escapeIdent(i.Name, '`') + "`"
 	}
 	return i.Name
 }
@@ -1328,9 +1669,46 @@ func (i *Ident) String(int) string {
 func (i *Ident) Accept(visitor ASTVisitor) error {
 	visitor.enter(i)
 	defer visitor.leave(i)
+	if i.Parameter != nil {
+		if err := i.Parameter.Accept(visitor); err != nil {
+			return err
+		}
+	}
 	return visitor.VisitIdent(i)
 }
 
+// QueryParameterExpr is a query parameter placeholder like {name:Type}.
+type QueryParameterExpr struct {
+	LBracePos Pos
+	RBracePos Pos
+	Name      *Ident
+	Type      Expr
+}
+
+func (q *QueryParameterExpr) Pos() Pos {
+	return q.LBracePos
+}
+
+func (q *QueryParameterExpr) End() Pos {
+	return q.RBracePos
+}
+
+func (q *QueryParameterExpr) String(level int) string {
+	return "{" + q.Name.String(level) + ":" + q.Type.String(level) + "}"
+}
+
+func (q *QueryParameterExpr) Accept(visitor ASTVisitor) error {
+	visitor.enter(q)
+	defer visitor.leave(q)
+	if err := q.Name.Accept(visitor); err != nil {
+		return err
+	}
+	if err := q.Type.Accept(visitor); err != nil {
+		return err
+	}
+	return visitor.VisitQueryParameterExpr(q)
+}
+
 type UUID struct {
 	Value *StringLiteral
 }
@@ -1360,6 +1738,8 @@ type CreateDatabase struct {
 	IfNotExists  bool // true if 'IF NOT EXISTS' is specified
 	OnCluster    *OnClusterExpr
 	Engine       *EngineExpr
+
+	Comments
 }
 
 func (c *CreateDatabase) Pos() Pos {
@@ -1389,7 +1769,7 @@ func (c *CreateDatabase) String(level int) string {
 		builder.WriteString(NewLine(level))
 		builder.WriteString(c.Engine.String(level))
 	}
-	return builder.String()
+	return c.formatComments(builder.String(), level)
 }
 
 func (c *CreateDatabase) Accept(visitor ASTVisitor) error {
@@ -1419,6 +1799,8 @@ type CreateTable struct {
 	Engine       *EngineExpr
 	SubQuery     *SubQueryExpr
 	HasTemporary bool
+
+	Comments
 }
 
 func (c *CreateTable) Pos() Pos {
@@ -1462,7 +1844,7 @@ func (c *CreateTable) String(level int) string {
 	if c.SubQuery != nil {
 		builder.WriteString(c.SubQuery.String(level))
 	}
-	return builder.String()
+	return c.formatComments(builder.String(), level)
 }
 
 func (c *CreateTable) Accept(visitor ASTVisitor) error {
@@ -1506,6 +1888,8 @@ type CreateMaterializedView struct {
 	Destination  *DestinationExpr
 	SubQuery     *SubQueryExpr
 	Populate     bool
+
+	Comments
 }
 
 func (c *CreateMaterializedView) Pos() Pos {
@@ -1549,7 +1933,7 @@ func (c *CreateMaterializedView) String(level int) string {
 	if c.SubQuery != nil {
 		builder.WriteString(c.SubQuery.String(level))
 	}
-	return builder.String()
+	return c.formatComments(builder.String(), level)
 }
 
 func (c *CreateMaterializedView) Accept(visitor ASTVisitor) error {
@@ -1595,6 +1979,8 @@ type CreateView struct {
 	OnCluster    *OnClusterExpr
 	TableSchema  *TableSchemaExpr
 	SubQuery     *SubQueryExpr
+
+	Comments
 }
 
 func (c *CreateView) Pos() Pos {
@@ -1634,7 +2020,7 @@ func (c *CreateView) String(level int) string {
 	if c.SubQuery != nil {
 		builder.WriteString(c.SubQuery.String(level))
 	}
-	return builder.String()
+	return c.formatComments(builder.String(), level)
 }
 
 func (c *CreateView) Accept(visitor ASTVisitor) error {
@@ -1673,6 +2059,8 @@ type CreateFunction struct {
 	OnCluster    *OnClusterExpr
 	Params       *ParamExprList
 	Expr         Expr
+
+	Comments
 }
 
 func (c *CreateFunction) Type() string {
@@ -1702,7 +2090,7 @@ func (c *CreateFunction) String(level int) string {
 	builder.WriteString(c.Params.String(level))
 	builder.WriteString(" -> ")
 	builder.WriteString(c.Expr.String(level))
-	return builder.String()
+	return c.formatComments(builder.String(), level)
 }
 
 func (c *CreateFunction) Accept(visitor ASTVisitor) error {
@@ -1873,6 +2261,8 @@ type CreateRole struct {
 	RoleNames         []*RoleName
 	AccessStorageType *Ident
 	Settings          []*RoleSetting
+
+	Comments
 }
 
 func (c *CreateRole) Pos() Pos {
@@ -1916,7 +2306,7 @@ func (c *CreateRole) String(level int) string {
 			builder.WriteString(setting.String(level))
 		}
 	}
-	return builder.String()
+	return c.formatComments(builder.String(), level)
 }
 
 func (c *CreateRole) Accept(visitor ASTVisitor) error {
@@ -1946,6 +2336,8 @@ type AlterRole struct {
 	IfExists        bool
 	RoleRenamePairs []*RoleRenamePair
 	Settings        []*RoleSetting
+
+	Comments
 }
 
 func (a *AlterRole) Pos() Pos {
@@ -1981,7 +2373,7 @@ func (a *AlterRole) String(level int) string {
 			builder.WriteString(setting.String(level))
 		}
 	}
-	return builder.String()
+	return a.formatComments(builder.String(), level)
 }
 
 func (a *AlterRole) Accept(visitor ASTVisitor) error {
@@ -2187,6 +2579,64 @@ func (n *NestedIdentifier) Accept(visitor ASTVisitor) error {
 	return visitor.VisitNestedIdentifier(n)
 }
 
+// JSONPathExpr is the subcolumn path of the JSON, Dynamic or Variant column with the optional type hint
+// like data.user.id.:Int64, or the sub-object selector like data.^user. The parser can't tell the column
+// from the path without the schema, so Names holds all the dot separated names and the column, maybe
+// qualified by the table, is a prefix of them. Up to three names without the type hint or the sub-object
+// selector are parsed as ColumnIdentifier.
+type JSONPathExpr struct {
+	Names []*Ident
+	// SubObjectPos is the position of ^, and Names[SubObjectIndex:] is the path of the selected sub-object.
+	SubObjectPos   Pos
+	SubObjectIndex int
+	TypeHint       Expr
+}
+
+func (j *JSONPathExpr) Pos() Pos {
+	return j.Names[0].Pos()
+}
+
+func (j *JSONPathExpr) End() Pos {
+	if j.TypeHint != nil {
+		return j.TypeHint.End()
+	}
+	return j.Names[len(j.Names)-1].End()
+}
+
+func (j *JSONPathExpr) String(level int) string {
+	var builder strings.Builder
+	for i, name := range j.Names {
+		if i > 0 {
+			builder.WriteByte('.')
+		}
+		if j.SubObjectPos != 0 && i == j.SubObjectIndex {
+			builder.WriteByte('^')
+		}
+		builder.WriteString(name.String(level))
+	}
+	if j.TypeHint != nil {
+		builder.WriteString(".:")
+		builder.WriteString(j.TypeHint.String(level))
+	}
+	return builder.String()
+}
+
+func (j *JSONPathExpr) Accept(visitor ASTVisitor) error {
+	visitor.enter(j)
+	defer visitor.leave(j)
+	for _, name := range j.Names {
+		if err := name.Accept(visitor); err != nil {
+			return err
+		}
+	}
+	if j.TypeHint != nil {
+		if err := j.TypeHint.Accept(visitor); err != nil {
+			return err
+		}
+	}
+	return visitor.VisitJSONPathExpr(j)
+}
+
 type ColumnIdentifier struct {
 	Database *Ident
 	Table    *Ident
@@ -2294,11 +2744,19 @@ func (t *TableSchemaExpr) String(level int) string {
 	if len(t.Columns) > 0 {
 		builder.WriteString("(")
 		for i, column := range t.Columns {
-			if i > 0 {
-				builder.WriteByte(',')
-			}
 			builder.WriteString(NewLine(level + 1))
+			separator := ","
+			if i == len(t.Columns)-1 {
+				separator = ""
+			}
+			if column, ok := column.(*Column); ok {
+				// the column prints its comments one level deeper
+				formatted, _ := formatCommentedListItem(column, separator, level, level+1)
+				builder.WriteString(formatted)
+				continue
+			}
 			builder.WriteString(column.String(level))
+			builder.WriteString(separator)
 		}
 		builder.WriteString(NewLine(level - 1))
 		builder.WriteByte(')')
@@ -2666,6 +3124,11 @@ type OrderByExpr struct {
 	OrderPos  Pos
 	Expr      Expr
 	Direction OrderDirection
+	// Nulls is FIRST or LAST of NULLS FIRST or NULLS LAST, empty if absent.
+	Nulls    string         `json:",omitempty"`
+	NullsEnd Pos            `json:",omitempty"`
+	Collate  *StringLiteral `json:",omitempty"`
+	WithFill *WithFillExpr  `json:",omitempty"`
 }
 
 func (o *OrderByExpr) Pos() Pos {
@@ -2673,6 +3136,14 @@ func (o *OrderByExpr) Pos() Pos {
 }
 
 func (o *OrderByExpr) End() Pos {
+	switch {
+	case o.WithFill != nil:
+		return o.WithFill.End()
+	case o.Collate != nil:
+		return o.Collate.End()
+	case o.Nulls != "":
+		return o.NullsEnd
+	}
 	return o.Expr.End()
 }
 
@@ -2683,6 +3154,18 @@ func (o *OrderByExpr) String(level int) string {
 		builder.WriteByte(' ')
 		builder.WriteString(string(o.Direction))
 	}
+	if o.Nulls != "" {
+		builder.WriteString(" NULLS ")
+		builder.WriteString(o.Nulls)
+	}
+	if o.Collate != nil {
+		builder.WriteString(" COLLATE ")
+		builder.WriteString(o.Collate.String(level))
+	}
+	if o.WithFill != nil {
+		builder.WriteByte(' ')
+		builder.WriteString(o.WithFill.String(level))
+	}
 	return builder.String()
 }
 
@@ -2692,34 +3175,187 @@ func (o *OrderByExpr) Accept(visitor ASTVisitor) error {
 	if err := o.Expr.Accept(visitor); err != nil {
 		return err
 	}
+	if o.Collate != nil {
+		if err := o.Collate.Accept(visitor); err != nil {
+			return err
+		}
+	}
+	if o.WithFill != nil {
+		if err := o.WithFill.Accept(visitor); err != nil {
+			return err
+		}
+	}
 	return visitor.VisitOrderByExpr(o)
 }
 
-type OrderByListExpr struct {
-	OrderPos Pos
-	ListEnd  Pos
-	Items    []Expr
+// WithFillExpr is WITH FILL [FROM expr] [TO expr] [STEP expr] [STALENESS expr] of the ORDER BY item
+type WithFillExpr struct {
+	WithPos      Pos
+	StatementEnd Pos
+	From         Expr
+	To           Expr
+	Step         Expr
+	Staleness    Expr
 }
 
-func (o *OrderByListExpr) Pos() Pos {
-	return o.OrderPos
+func (w *WithFillExpr) Pos() Pos {
+	return w.WithPos
 }
 
-func (o *OrderByListExpr) End() Pos {
-	return o.ListEnd
+func (w *WithFillExpr) End() Pos {
+	return w.StatementEnd
 }
 
-func (o *OrderByListExpr) String(level int) string {
+func (w *WithFillExpr) String(level int) string {
 	var builder strings.Builder
-	builder.WriteString("ORDER BY ")
-	for i, item := range o.Items {
-		builder.WriteString(item.String(level))
-		if i != len(o.Items)-1 {
-			builder.WriteByte(',')
-			builder.WriteByte(' ')
-		}
+	builder.WriteString("WITH FILL")
+	if w.From != nil {
+		builder.WriteString(" FROM ")
+		builder.WriteString(w.From.String(level))
 	}
-	return builder.String()
+	if w.To != nil {
+		builder.WriteString(" TO ")
+		builder.WriteString(w.To.String(level))
+	}
+	if w.Step != nil {
+		builder.WriteString(" STEP ")
+		builder.WriteString(w.Step.String(level))
+	}
+	if w.Staleness != nil {
+		builder.WriteString(" STALENESS ")
+		builder.WriteString(w.Staleness.String(level))
+	}
+	return builder.String()
+}
+
+func (w *WithFillExpr) Accept(visitor ASTVisitor) error {
+	visitor.enter(w)
+	defer visitor.leave(w)
+	for _, expr := range []Expr{w.From, w.To, w.Step, w.Staleness} {
+		if expr == nil {
+			continue
+		}
+		if err := expr.Accept(visitor); err != nil {
+			return err
+		}
+	}
+	return visitor.VisitWithFillExpr(w)
+}
+
+// InterpolateExpr is INTERPOLATE [(column [AS expr], ...)] following the ORDER BY items with WITH FILL,
+// all the columns are interpolated if Items is empty.
+type InterpolateExpr struct {
+	InterpolatePos Pos
+	StatementEnd   Pos
+	Items          []*InterpolateItem
+}
+
+func (i *InterpolateExpr) Pos() Pos {
+	return i.InterpolatePos
+}
+
+func (i *InterpolateExpr) End() Pos {
+	return i.StatementEnd
+}
+
+func (i *InterpolateExpr) String(level int) string {
+	var builder strings.Builder
+	builder.WriteString("INTERPOLATE")
+	if len(i.Items) > 0 {
+		builder.WriteString(" (")
+		for j, item := range i.Items {
+			if j > 0 {
+				builder.WriteString(", ")
+			}
+			builder.WriteString(item.String(level))
+		}
+		builder.WriteByte(')')
+	}
+	return builder.String()
+}
+
+func (i *InterpolateExpr) Accept(visitor ASTVisitor) error {
+	visitor.enter(i)
+	defer visitor.leave(i)
+	for _, item := range i.Items {
+		if err := item.Accept(visitor); err != nil {
+			return err
+		}
+	}
+	return visitor.VisitInterpolateExpr(i)
+}
+
+// InterpolateItem is the column filled by the expression like v AS v + 1, the column keeps the value
+// of the previous row if Expr is nil.
+type InterpolateItem struct {
+	Column *Ident
+	Expr   Expr
+}
+
+func (i *InterpolateItem) Pos() Pos {
+	return i.Column.Pos()
+}
+
+func (i *InterpolateItem) End() Pos {
+	if i.Expr != nil {
+		return i.Expr.End()
+	}
+	return i.Column.End()
+}
+
+func (i *InterpolateItem) String(level int) string {
+	if i.Expr == nil {
+		return i.Column.String(level)
+	}
+	return i.Column.String(level) + " AS " + i.Expr.String(level)
+}
+
+func (i *InterpolateItem) Accept(visitor ASTVisitor) error {
+	visitor.enter(i)
+	defer visitor.leave(i)
+	if err := i.Column.Accept(visitor); err != nil {
+		return err
+	}
+	if i.Expr != nil {
+		if err := i.Expr.Accept(visitor); err != nil {
+			return err
+		}
+	}
+	return visitor.VisitInterpolateItem(i)
+}
+
+type OrderByListExpr struct {
+	OrderPos    Pos
+	ListEnd     Pos
+	Items       []Expr
+	Interpolate *InterpolateExpr `json:",omitempty"`
+
+	Comments
+}
+
+func (o *OrderByListExpr) Pos() Pos {
+	return o.OrderPos
+}
+
+func (o *OrderByListExpr) End() Pos {
+	return o.ListEnd
+}
+
+func (o *OrderByListExpr) String(level int) string {
+	var builder strings.Builder
+	builder.WriteString("ORDER BY ")
+	for i, item := range o.Items {
+		builder.WriteString(item.String(level))
+		if i != len(o.Items)-1 {
+			builder.WriteByte(',')
+			builder.WriteByte(' ')
+		}
+	}
+	if o.Interpolate != nil {
+		builder.WriteByte(' ')
+		builder.WriteString(o.Interpolate.String(level))
+	}
+	return o.formatComments(builder.String(), level)
 }
 
 func (o *OrderByListExpr) Accept(visitor ASTVisitor) error {
@@ -2730,6 +3366,11 @@ func (o *OrderByListExpr) Accept(visitor ASTVisitor) error {
 			return err
 		}
 	}
+	if o.Interpolate != nil {
+		if err := o.Interpolate.Accept(visitor); err != nil {
+			return err
+		}
+	}
 	return visitor.VisitOrderByListExpr(o)
 }
 
@@ -2771,6 +3412,8 @@ type SettingsExprList struct {
 	SettingsPos Pos
 	ListEnd     Pos
 	Items       []*SettingsExpr
+
+	Comments
 }
 
 func (s *SettingsExprList) Pos() Pos {
@@ -2790,7 +3433,7 @@ func (s *SettingsExprList) String(level int) string {
 		}
 		builder.WriteString(item.String(level))
 	}
-	return builder.String()
+	return s.formatComments(builder.String(), level)
 }
 
 func (s *SettingsExprList) Accept(visitor ASTVisitor) error {
@@ -2804,6 +3447,8 @@ func (s *SettingsExprList) Accept(visitor ASTVisitor) error {
 	return visitor.VisitSettingsExprList(s)
 }
 
+// ParamExprList is the parenthesized list of a function call. For the parametric aggregate function
+// like quantiles(0.5, 0.9)(latency), Items are the parameters and ColumnArgList is the arguments.
 type ParamExprList struct {
 	LeftParenPos  Pos
 	RightParenPos Pos
@@ -2816,19 +3461,20 @@ func (f *ParamExprList) Pos() Pos {
 }
 
 func (f *ParamExprList) End() Pos {
+	if f.ColumnArgList != nil {
+		return f.ColumnArgList.End()
+	}
 	return f.RightParenPos
 }
 
 func (f *ParamExprList) String(level int) string {
 	var builder strings.Builder
 	builder.WriteString("(")
-	for i, item := range f.Items.Items {
-		if i > 0 {
-			builder.WriteString(", ")
-		}
-		builder.WriteString(item.String(level))
-	}
+	builder.WriteString(f.Items.String(level))
 	builder.WriteString(")")
+	if f.ColumnArgList != nil {
+		builder.WriteString(f.ColumnArgList.String(level))
+	}
 	return builder.String()
 }
 
@@ -2846,6 +3492,95 @@ func (f *ParamExprList) Accept(visitor ASTVisitor) error {
 	return visitor.VisitParamExprList(f)
 }
 
+// TupleLiteral is a tuple like (1, 'x'), a single element tuple is written as (x,)
+type TupleLiteral struct {
+	LeftParenPos  Pos
+	RightParenPos Pos
+	Items         []Expr
+	// ItemComments are the comments of the items by their indexes, the items without comments are nil
+	ItemComments []*Comments `json:",omitempty"`
+}
+
+func (t *TupleLiteral) Pos() Pos {
+	return t.LeftParenPos
+}
+
+func (t *TupleLiteral) End() Pos {
+	return t.RightParenPos
+}
+
+func (t *TupleLiteral) String(level int) string {
+	var builder strings.Builder
+	builder.WriteByte('(')
+	lastSeparator := ""
+	if len(t.Items) == 1 {
+		lastSeparator = ","
+	}
+	formatListItems(&builder, t.Items, t.ItemComments, lastSeparator, level)
+	builder.WriteByte(')')
+	return builder.String()
+}
+
+func (t *TupleLiteral) Accept(visitor ASTVisitor) error {
+	visitor.enter(t)
+	defer visitor.leave(t)
+	for _, item := range t.Items {
+		if err := item.Accept(visitor); err != nil {
+			return err
+		}
+	}
+	return visitor.VisitTupleLiteral(t)
+}
+
+type KeyValue struct {
+	Key   Expr
+	Value Expr
+}
+
+// MapLiteral is a map like {'a': 1, 'b': 2}
+type MapLiteral struct {
+	LeftBracePos  Pos
+	RightBracePos Pos
+	KeyValues     []KeyValue
+}
+
+func (m *MapLiteral) Pos() Pos {
+	return m.LeftBracePos
+}
+
+func (m *MapLiteral) End() Pos {
+	return m.RightBracePos
+}
+
+func (m *MapLiteral) String(level int) string {
+	var builder strings.Builder
+	builder.WriteByte('{')
+	for i, keyValue := range m.KeyValues {
+		if i > 0 {
+			builder.WriteString(", ")
+		}
+		builder.WriteString(keyValue.Key.String(level))
+		builder.WriteString(": ")
+		builder.WriteString(keyValue.Value.String(level))
+	}
+	builder.WriteByte('}')
+	return builder.String()
+}
+
+func (m *MapLiteral) Accept(visitor ASTVisitor) error {
+	visitor.enter(m)
+	defer visitor.leave(m)
+	for _, keyValue := range m.KeyValues {
+		if err := keyValue.Key.Accept(visitor); err != nil {
+			return err
+		}
+		if err := keyValue.Value.Accept(visitor); err != nil {
+			return err
+		}
+	}
+	return visitor.VisitMapLiteral(m)
+}
+
 type ArrayParamList struct {
 	LeftBracketPos  Pos
 	RightBracketPos Pos
@@ -2863,12 +3598,7 @@ func (a *ArrayParamList) End() Pos {
 func (a *ArrayParamList) String(level int) string {
 	var builder strings.Builder
 	builder.WriteString("[")
-	for i, item := range a.Items.Items {
-		if i > 0 {
-			builder.WriteString(", ")
-		}
-		builder.WriteString(item.String(level))
-	}
+	builder.WriteString(a.Items.String(level))
 	builder.WriteString("]")
 	return builder.String()
 }
@@ -2882,6 +3612,7 @@ func (a *ArrayParamList) Accept(visitor ASTVisitor) error {
 	return visitor.VisitArrayParamList(a)
 }
 
+// Deprecated: array subscripts are parsed as IndexExpr.
 type ObjectParams struct {
 	Object Expr
 	Params *ArrayParamList
@@ -2914,9 +3645,87 @@ func (o *ObjectParams) Accept(visitor ASTVisitor) error {
 	return visitor.VisitObjectParams(o)
 }
 
+// IndexExpr is the subscript of an array, a map or a tuple like arr[1] or m['key'].
+type IndexExpr struct {
+	Object          Expr
+	LeftBracketPos  Pos
+	RightBracketPos Pos
+	Index           Expr
+}
+
+func (i *IndexExpr) Pos() Pos {
+	return i.Object.Pos()
+}
+
+func (i *IndexExpr) End() Pos {
+	return i.RightBracketPos
+}
+
+func (i *IndexExpr) String(level int) string {
+	var builder strings.Builder
+	builder.WriteString(formatOperand(i.Object, precedencePostfix, level))
+	builder.WriteByte('[')
+	builder.WriteString(i.Index.String(level))
+	builder.WriteByte(']')
+	return builder.String()
+}
+
+func (i *IndexExpr) Accept(visitor ASTVisitor) error {
+	visitor.enter(i)
+	defer visitor.leave(i)
+	if err := i.Object.Accept(visitor); err != nil {
+		return err
+	}
+	if err := i.Index.Accept(visitor); err != nil {
+		return err
+	}
+	return visitor.VisitIndexExpr(i)
+}
+
+// TupleElementExpr is the access to a tuple element by the 1-based position or the name like t.1 or f(x).name.
+type TupleElementExpr struct {
+	Tuple   Expr
+	DotPos  Pos
+	Element Expr // *NumberLiteral or *Ident
+}
+
+func (t *TupleElementExpr) Pos() Pos {
+	return t.Tuple.Pos()
+}
+
+func (t *TupleElementExpr) End() Pos {
+	return t.Element.End()
+}
+
+func (t *TupleElementExpr) String(level int) string {
+	var builder strings.Builder
+	builder.WriteString(formatOperand(t.Tuple, precedencePostfix, level))
+	builder.WriteByte('.')
+	builder.WriteString(t.Element.String(level))
+	return builder.String()
+}
+
+func (t *TupleElementExpr) Accept(visitor ASTVisitor) error {
+	visitor.enter(t)
+	defer visitor.leave(t)
+	if err := t.Tuple.Accept(visitor); err != nil {
+		return err
+	}
+	if err := t.Element.Accept(visitor); err != nil {
+		return err
+	}
+	return visitor.VisitTupleElementExpr(t)
+}
+
 type FunctionExpr struct {
 	Name   *Ident
 	Params *ParamExprList
+	// Nulls is RESPECT or IGNORE of the RESPECT NULLS or IGNORE NULLS modifier, empty if absent.
+	Nulls    string `json:",omitempty"`
+	NullsPos Pos    `json:",omitempty"`
+	NullsEnd Pos    `json:",omitempty"`
+	// Filter is the FILTER (WHERE cond) clause of the aggregate function.
+	Filter *FilterExpr `json:",omitempty"`
 }
 
 func (f *FunctionExpr) Pos() Pos {
@@ -2924,13 +3733,45 @@ func (f *FunctionExpr) Pos() Pos {
 }
 
 func (f *FunctionExpr) End() Pos {
-	return f.Params.RightParenPos
+	if f.Filter != nil {
+		return f.Filter.End()
+	}
+	if f.Nulls != "" {
+		return f.NullsEnd
+	}
+	return f.Params.End()
+}
+
+// Parameters returns the parameters of the parametric aggregate function like 0.5 and 0.9 of
+// quantiles(0.5, 0.9)(latency), or nil if the function is called with the arguments only.
+func (f *FunctionExpr) Parameters() []Expr {
+	if f.Params.ColumnArgList == nil {
+		return nil
+	}
+	return f.Params.Items.Items
+}
+
+// Arguments returns the arguments of the function like latency of quantiles(0.5, 0.9)(latency).
+func (f *FunctionExpr) Arguments() []Expr {
+	if f.Params.ColumnArgList != nil {
+		return f.Params.ColumnArgList.Items
+	}
+	return f.Params.Items.Items
 }
 
 func (f *FunctionExpr) String(level int) string {
 	var builder strings.Builder
 	builder.WriteString(f.Name.String(level))
 	builder.WriteString(f.Params.String(level))
+	if f.Nulls != "" {
+		builder.WriteByte(' ')
+		builder.WriteString(f.Nulls)
+		builder.WriteString(" NULLS")
+	}
+	if f.Filter != nil {
+		builder.WriteByte(' ')
+		builder.WriteString(f.Filter.String(level))
+	}
 	return builder.String()
 }
 
@@ -2943,9 +3784,43 @@ func (f *FunctionExpr) Accept(visitor ASTVisitor) error {
 	if err := f.Params.Accept(visitor); err != nil {
 		return err
 	}
+	if f.Filter != nil {
+		if err := f.Filter.Accept(visitor); err != nil {
+			return err
+		}
+	}
 	return visitor.VisitFunctionExpr(f)
 }
 
+// FilterExpr is the FILTER (WHERE cond) clause which limits the rows fed to the aggregate function
+type FilterExpr struct {
+	FilterPos     Pos
+	WherePos      Pos
+	RightParenPos Pos
+	Expr          Expr
+}
+
+func (f *FilterExpr) Pos() Pos {
+	return f.FilterPos
+}
+
+func (f *FilterExpr) End() Pos {
+	return f.RightParenPos
+}
+
+func (f *FilterExpr) String(level int) string {
+	return "FILTER (WHERE " + f.Expr.String(level) + ")"
+}
+
+func (f *FilterExpr) Accept(visitor ASTVisitor) error {
+	visitor.enter(f)
+	defer visitor.leave(f)
+	if err := f.Expr.Accept(visitor); err != nil {
+		return err
+	}
+	return visitor.VisitFilterExpr(f)
+}
+
 type WindowFunctionExpr struct {
 	Function *FunctionExpr
 	OverPos  Pos
@@ -2995,6 +3870,8 @@ type Column struct {
 
 	Comment          *StringLiteral
 	CompressionCodec *Ident
+
+	Comments
 }
 
 func (c *Column) Pos() Pos {
@@ -3033,7 +3910,7 @@ func (c *Column) String(level int) string {
 		builder.WriteString(" COMMENT ")
 		builder.WriteString(c.Comment.String(level))
 	}
-	return builder.String()
+	return c.formatComments(builder.String(), level+1)
 }
 
 func (c *Column) Accept(visitor ASTVisitor) error {
@@ -3327,6 +4204,104 @@ func (n *NumberLiteral) String(int) string {
 	return n.Literal
 }
 
+// isInfOrNaN reports whether the literal is inf, infinity or nan, and the sign of it.
+func (n *NumberLiteral) isInfOrNaN() (isInf, isNaN bool, sign int) {
+	literal := strings.ToLower(n.Literal)
+	sign = 1
+	if strings.HasPrefix(literal, "-") {
+		sign = -1
+	}
+	literal = strings.TrimLeft(literal, "+-")
+	return literal == "inf" || literal == "infinity", literal == "nan", sign
+}
+
+func (n *NumberLiteral) rat(fn string) (*big.Rat, error) {
+	if isInf, isNaN, _ := n.isInfOrNaN(); isInf || isNaN {
+		return nil, &strconv.NumError{Func: fn, Num: n.Literal, Err: strconv.ErrRange}
+	}
+	r, ok := new(big.Rat).SetString(strings.ReplaceAll(n.Literal, "_", ""))
+	if !ok {
+		return nil, &strconv.NumError{Func: fn, Num: n.Literal, Err: strconv.ErrSyntax}
+	}
+	return r, nil
+}
+
+func (n *NumberLiteral) bigInt(fn string) (*big.Int, error) {
+	r, err := n.rat(fn)
+	if err != nil {
+		return nil, err
+	}
+	if !r.IsInt() {
+		return nil, &strconv.NumError{Func: fn, Num: n.Literal, Err: strconv.ErrSyntax}
+	}
+	return r.Num(), nil
+}
+
+// BigInt returns the value of an integral number, the error is a *strconv.NumError if the
+// number has a fractional part or is inf or nan.
+func (n *NumberLiteral) BigInt() (*big.Int, error) {
+	return n.bigInt("BigInt")
+}
+
+// Int64 returns the value of an integral number, the error wraps strconv.ErrRange if it overflows int64.
+func (n *NumberLiteral) Int64() (int64, error) {
+	value, err := n.bigInt("Int64")
+	if err != nil {
+		return 0, err
+	}
+	if !value.IsInt64() {
+		return 0, &strconv.NumError{Func: "Int64", Num: n.Literal, Err: strconv.ErrRange}
+	}
+	return value.Int64(), nil
+}
+
+// Uint64 returns the value of an integral number, the error wraps strconv.ErrRange if it overflows uint64.
+func (n *NumberLiteral) Uint64() (uint64, error) {
+	value, err := n.bigInt("Uint64")
+	if err != nil {
+		return 0, err
+	}
+	if !value.IsUint64() {
+		return 0, &strconv.NumError{Func: "Uint64", Num: n.Literal, Err: strconv.ErrRange}
+	}
+	return value.Uint64(), nil
+}
+
+// Float64 returns the nearest float64 value of the number, the error wraps strconv.ErrRange
+// if it overflows float64.
+func (n *NumberLiteral) Float64() (float64, error) {
+	if isInf, isNaN, sign := n.isInfOrNaN(); isInf {
+		return math.Inf(sign), nil
+	} else if isNaN {
+		return math.NaN(), nil
+	}
+	literal := strings.ReplaceAll(n.Literal, "_", "")
+	switch n.Base {
+	case 2:
+		// ParseFloat doesn't take binary numbers, which are integers
+		if value, err := strconv.ParseInt(literal, 0, 64); err == nil {
+			return float64(value), nil
+		}
+		value, err := n.bigInt("Float64")
+		if err != nil {
+			return 0, err
+		}
+		f, _ := new(big.Float).SetInt(value).Float64()
+		return f, nil
+	case 16:
+		// ParseFloat requires the exponent of hexadecimal numbers
+		if !strings.ContainsAny(literal, "pP") {
+			literal += "p0"
+		}
+	}
+	value, err := strconv.ParseFloat(literal, 64)
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		return value, &strconv.NumError{Func: "Float64", Num: n.Literal, Err: numErr.Err}
+	}
+	return value, err
+}
+
 func (n *NumberLiteral) Accept(visitor ASTVisitor) error {
 	visitor.enter(n)
 	defer visitor.leave(n)
@@ -3336,7 +4311,10 @@ func (n *NumberLiteral) Accept(visitor ASTVisitor) error {
 type StringLiteral struct {
 	LiteralPos Pos
 	LiteralEnd Pos
-	Literal    string
+	Literal    string // decoded value
+	Raw        string // source text between the quotes
+	QuoteType  int    // SingleQuote or Heredoc
+	HeredocTag string
 }
 
 func (s *StringLiteral) Pos() Pos {
@@ -3348,7 +4326,10 @@ func (s *StringLiteral) End() Pos {
 }
 
 func (s *StringLiteral) String(int) string {
-	return "'" + s.Literal + "'"
+	if s.QuoteType == Heredoc {
+		return "$" + s.HeredocTag + "$" + s.Literal + "$" + s.HeredocTag + "$"
+	}
+	return "'" + escapeString(s.Literal) + "'"
 }
 
 func (s *StringLiteral) Accept(visitor ASTVisitor) error {
@@ -3643,9 +4624,12 @@ func (c *ColumnArgList) End() Pos {
 func (c *ColumnArgList) String(level int) string {
 	var builder strings.Builder
 	builder.WriteByte('(')
+	if c.Distinct {
+		builder.WriteString("DISTINCT ")
+	}
 	for i, item := range c.Items {
 		if i > 0 {
-			builder.WriteByte(',')
+			builder.WriteString(", ")
 		}
 		builder.WriteString(item.String(level))
 	}
@@ -3669,6 +4653,13 @@ type ColumnExprList struct {
 	ListEnd     Pos
 	HasDistinct bool
 	Items       []Expr
+	// ItemComments are the comments of the items by their indexes, the items without comments are nil
+	ItemComments []*Comments `json:",omitempty"`
+}
+
+// itemComments returns the comments of the i-th item, or nil if it has no comments
+func (c *ColumnExprList) itemComments(i int) *Comments {
+	return itemCommentsAt(c.ItemComments, i)
 }
 
 func (c *ColumnExprList) Pos() Pos {
@@ -3684,13 +4675,32 @@ func (c *ColumnExprList) String(level int) string {
 	if c.HasDistinct {
 		builder.WriteString("DISTINCT ")
 	}
-	for i, item := range c.Items {
-		builder.WriteString(item.String(level))
-		if i != len(c.Items)-1 {
-			builder.WriteString(", ")
+	formatListItems(&builder, c.Items, c.ItemComments, "", level)
+	return builder.String()
+}
+
+func itemCommentsAt(itemComments []*Comments, i int) *Comments {
+	if i >= len(itemComments) {
+		return nil
+	}
+	return itemComments[i]
+}
+
+// formatListItems prints the comma separated items with their comments, the last item is followed by lastSeparator.
+func formatListItems(builder *strings.Builder, items []Expr, itemComments []*Comments, lastSeparator string, level int) {
+	for i, item := range items {
+		separator := ","
+		if i == len(items)-1 {
+			separator = lastSeparator
+		}
+		formatted, endsWithLineComment := itemCommentsAt(itemComments, i).formatListItem(item.String(level), separator, level)
+		builder.WriteString(formatted)
+		if endsWithLineComment {
+			builder.WriteString(NewLine(level))
+		} else if i != len(items)-1 {
+			builder.WriteByte(' ')
 		}
 	}
-	return builder.String()
 }
 
 func (c *ColumnExprList) Accept(visitor ASTVisitor) error {
@@ -3936,6 +4946,8 @@ type CreateLiveView struct {
 	TableSchema  *TableSchemaExpr
 	WithTimeout  *WithTimeoutExpr
 	SubQuery     *SubQueryExpr
+
+	Comments
 }
 
 func (c *CreateLiveView) Type() string {
@@ -3982,7 +4994,7 @@ func (c *CreateLiveView) String(level int) string {
 		builder.WriteString(c.SubQuery.String(level))
 	}
 
-	return builder.String()
+	return c.formatComments(builder.String(), level)
 }
 
 func (c *CreateLiveView) Accept(visitor ASTVisitor) error {
@@ -4072,7 +5084,13 @@ func (t *TableExpr) End() Pos {
 
 func (t *TableExpr) String(level int) string {
 	var builder strings.Builder
-	builder.WriteString(t.Expr.String(level + 1))
+	if _, isSelect := t.Expr.(*SelectQuery); isSelect {
+		builder.WriteByte('(')
+		builder.WriteString(t.Expr.String(level + 1))
+		builder.WriteByte(')')
+	} else {
+		builder.WriteString(t.Expr.String(level + 1))
+	}
 	if t.Alias != nil {
 		builder.WriteByte(' ')
 		builder.WriteString(t.Alias.String(level + 1))
@@ -4272,6 +5290,8 @@ func (j *JoinConstraintExpr) Accept(visitor ASTVisitor) error {
 type FromExpr struct {
 	FromPos Pos
 	Expr    Expr
+
+	Comments
 }
 
 func (f *FromExpr) Pos() Pos {
@@ -4287,7 +5307,7 @@ func (f *FromExpr) String(level int) string {
 	builder.WriteString("FROM")
 	builder.WriteString(NewLine(level + 1))
 	builder.WriteString(f.Expr.String(level + 1))
-	return builder.String()
+	return f.formatComments(builder.String(), level)
 }
 
 func (f *FromExpr) Accept(visitor ASTVisitor) error {
@@ -4314,7 +5334,7 @@ func (n *IsNullExpr) End() Pos {
 
 func (n *IsNullExpr) String(level int) string {
 	var builder strings.Builder
-	builder.WriteString(n.Expr.String(level))
+	builder.WriteString(formatOperand(n.Expr, precedenceIs, level))
 	builder.WriteString(" IS NULL")
 	return builder.String()
 }
@@ -4343,7 +5363,7 @@ func (n *IsNotNullExpr) End() Pos {
 
 func (n *IsNotNullExpr) String(level int) string {
 	var builder strings.Builder
-	builder.WriteString(n.Expr.String(level))
+	builder.WriteString(formatOperand(n.Expr, precedenceIs, level))
 	builder.WriteString(" IS NOT NULL")
 	return builder.String()
 }
@@ -4357,6 +5377,219 @@ func (n *IsNotNullExpr) Accept(visitor ASTVisitor) error {
 	return visitor.VisitIsNotNullExpr(n)
 }
 
+// ColumnsMatcher selects the columns whose names match a regular expression like COLUMNS('^metric_'),
+// or the listed columns like COLUMNS(a, b), only one of Pattern and Columns is set.
+type ColumnsMatcher struct {
+	ColumnsPos    Pos
+	RightParenPos Pos
+	Pattern       *StringLiteral
+	Columns       *ColumnExprList
+}
+
+func (c *ColumnsMatcher) Pos() Pos {
+	return c.ColumnsPos
+}
+
+func (c *ColumnsMatcher) End() Pos {
+	return c.RightParenPos
+}
+
+func (c *ColumnsMatcher) String(level int) string {
+	var builder strings.Builder
+	builder.WriteString("COLUMNS(")
+	if c.Pattern != nil {
+		builder.WriteString(c.Pattern.String(level))
+	} else {
+		builder.WriteString(c.Columns.String(level))
+	}
+	builder.WriteByte(')')
+	return builder.String()
+}
+
+func (c *ColumnsMatcher) Accept(visitor ASTVisitor) error {
+	visitor.enter(c)
+	defer visitor.leave(c)
+	if c.Pattern != nil {
+		if err := c.Pattern.Accept(visitor); err != nil {
+			return err
+		}
+	} else {
+		if err := c.Columns.Accept(visitor); err != nil {
+			return err
+		}
+	}
+	return visitor.VisitColumnsMatcher(c)
+}
+
+// ColumnTransformerExpr is the chain of transformers applied to the matched columns
+// like * EXCEPT (secret) REPLACE (round(x, 2) AS x) or COLUMNS('^metric_') APPLY(sum).
+type ColumnTransformerExpr struct {
+	Columns      Expr   // *, t.* or COLUMNS(...)
+	Transformers []Expr // *ApplyTransformer, *ExceptTransformer or *ReplaceTransformer, in order
+}
+
+func (c *ColumnTransformerExpr) Pos() Pos {
+	return c.Columns.Pos()
+}
+
+func (c *ColumnTransformerExpr) End() Pos {
+	return c.Transformers[len(c.Transformers)-1].End()
+}
+
+func (c *ColumnTransformerExpr) String(level int) string {
+	var builder strings.Builder
+	builder.WriteString(c.Columns.String(level))
+	for _, transformer := range c.Transformers {
+		builder.WriteByte(' ')
+		builder.WriteString(transformer.String(level))
+	}
+	return builder.String()
+}
+
+func (c *ColumnTransformerExpr) Accept(visitor ASTVisitor) error {
+	visitor.enter(c)
+	defer visitor.leave(c)
+	if err := c.Columns.Accept(visitor); err != nil {
+		return err
+	}
+	for _, transformer := range c.Transformers {
+		if err := transformer.Accept(visitor); err != nil {
+			return err
+		}
+	}
+	return visitor.VisitColumnTransformerExpr(c)
+}
+
+// ApplyTransformer calls the function on each matched column like APPLY(sum) or APPLY(x -> x + 1)
+type ApplyTransformer struct {
+	ApplyPos     Pos
+	StatementEnd Pos
+	HasParen     bool
+	Func         Expr // function name, parametric function like quantile(0.9), or lambda
+}
+
+func (a *ApplyTransformer) Pos() Pos {
+	return a.ApplyPos
+}
+
+func (a *ApplyTransformer) End() Pos {
+	return a.StatementEnd
+}
+
+func (a *ApplyTransformer) String(level int) string {
+	if a.HasParen {
+		return "APPLY(" + a.Func.String(level) + ")"
+	}
+	return "APPLY " + a.Func.String(level)
+}
+
+func (a *ApplyTransformer) Accept(visitor ASTVisitor) error {
+	visitor.enter(a)
+	defer visitor.leave(a)
+	if err := a.Func.Accept(visitor); err != nil {
+		return err
+	}
+	return visitor.VisitApplyTransformer(a)
+}
+
+// ExceptTransformer removes the columns from the matched columns like EXCEPT (a, b) or EXCEPT('^tmp_')
+type ExceptTransformer struct {
+	ExceptPos    Pos
+	StatementEnd Pos
+	Strict       bool
+	HasParen     bool
+	Columns      []Expr // column names, or a string literal of the regular expression
+}
+
+func (e *ExceptTransformer) Pos() Pos {
+	return e.ExceptPos
+}
+
+func (e *ExceptTransformer) End() Pos {
+	return e.StatementEnd
+}
+
+func (e *ExceptTransformer) String(level int) string {
+	var builder strings.Builder
+	builder.WriteString("EXCEPT ")
+	if e.Strict {
+		builder.WriteString("STRICT ")
+	}
+	if e.HasParen {
+		builder.WriteByte('(')
+	}
+	for i, column := range e.Columns {
+		if i > 0 {
+			builder.WriteString(", ")
+		}
+		builder.WriteString(column.String(level))
+	}
+	if e.HasParen {
+		builder.WriteByte(')')
+	}
+	return builder.String()
+}
+
+func (e *ExceptTransformer) Accept(visitor ASTVisitor) error {
+	visitor.enter(e)
+	defer visitor.leave(e)
+	for _, column := range e.Columns {
+		if err := column.Accept(visitor); err != nil {
+			return err
+		}
+	}
+	return visitor.VisitExceptTransformer(e)
+}
+
+// ReplaceTransformer replaces the matched columns with the expressions like REPLACE (round(x, 2) AS x)
+type ReplaceTransformer struct {
+	ReplacePos   Pos
+	StatementEnd Pos
+	Strict       bool
+	HasParen     bool
+	Replaces     []*AliasExpr
+}
+
+func (r *ReplaceTransformer) Pos() Pos {
+	return r.ReplacePos
+}
+
+func (r *ReplaceTransformer) End() Pos {
+	return r.StatementEnd
+}
+
+func (r *ReplaceTransformer) String(level int) string {
+	var builder strings.Builder
+	builder.WriteString("REPLACE ")
+	if r.Strict {
+		builder.WriteString("STRICT ")
+	}
+	if r.HasParen {
+		builder.WriteByte('(')
+	}
+	for i, replace := range r.Replaces {
+		if i > 0 {
+			builder.WriteString(", ")
+		}
+		builder.WriteString(replace.String(level))
+	}
+	if r.HasParen {
+		builder.WriteByte(')')
+	}
+	return builder.String()
+}
+
+func (r *ReplaceTransformer) Accept(visitor ASTVisitor) error {
+	visitor.enter(r)
+	defer visitor.leave(r)
+	for _, replace := range r.Replaces {
+		if err := replace.Accept(visitor); err != nil {
+			return err
+		}
+	}
+	return visitor.VisitReplaceTransformer(r)
+}
+
 type AliasExpr struct {
 	Expr     Expr
 	AliasPos Pos
@@ -4400,111 +5633,285 @@ func (a *AliasExpr) Accept(visitor ASTVisitor) error {
 type WhereExpr struct {
 	WherePos Pos
 	Expr     Expr
+
+	Comments
+}
+
+func (w *WhereExpr) Pos() Pos {
+	return w.WherePos
+}
+
+func (w *WhereExpr) End() Pos {
+	return w.Expr.End()
+}
+
+func (w *WhereExpr) String(level int) string {
+	var builder strings.Builder
+	builder.WriteString("WHERE")
+	builder.WriteString(NewLine(level + 1))
+	builder.WriteString(w.Expr.String(level))
+	return w.formatComments(builder.String(), level)
+}
+
+func (w *WhereExpr) Accept(visitor ASTVisitor) error {
+	visitor.enter(w)
+	defer visitor.leave(w)
+	if err := w.Expr.Accept(visitor); err != nil {
+		return err
+	}
+	return visitor.VisitWhereExpr(w)
+}
+
+type PrewhereExpr struct {
+	PrewherePos Pos
+	Expr        Expr
+
+	Comments
+}
+
+func (w *PrewhereExpr) Pos() Pos {
+	return w.PrewherePos
+}
+
+func (w *PrewhereExpr) End() Pos {
+	return w.Expr.End()
+}
+
+func (w *PrewhereExpr) String(level int) string {
+	return w.formatComments("PREWHERE "+w.Expr.String(level+1), level)
+}
+
+func (w *PrewhereExpr) Accept(visitor ASTVisitor) error {
+	visitor.enter(w)
+	defer visitor.leave(w)
+	if err := w.Expr.Accept(visitor); err != nil {
+		return err
+	}
+	return visitor.VisitPrewhereExpr(w)
+}
+
+type GroupByExpr struct {
+	GroupByPos   Pos
+	StatementEnd Pos
+	// Expr is the *ColumnExprList of the keys, or one of *RollupExpr, *CubeExpr and *GroupingSetsExpr,
+	// it's nil for GROUP BY ALL.
+	Expr       Expr
+	All        bool
+	WithCube   bool
+	WithRollup bool
+	WithTotals bool
+
+	Comments
+}
+
+func (g *GroupByExpr) Pos() Pos {
+	return g.GroupByPos
+}
+
+func (g *GroupByExpr) End() Pos {
+	return g.StatementEnd
+}
+
+func (g *GroupByExpr) String(level int) string {
+	var builder strings.Builder
+	builder.WriteString("GROUP BY ")
+	if g.All {
+		builder.WriteString("ALL")
+	} else {
+		builder.WriteString(g.Expr.String(level))
+	}
+	if g.WithCube {
+		builder.WriteString(" WITH CUBE")
+	}
+	if g.WithRollup {
+		builder.WriteString(" WITH ROLLUP")
+	}
+	if g.WithTotals {
+		builder.WriteString(" WITH TOTALS")
+	}
+	return g.formatComments(builder.String(), level)
+}
+
+func (g *GroupByExpr) Accept(visitor ASTVisitor) error {
+	visitor.enter(g)
+	defer visitor.leave(g)
+	if g.Expr != nil {
+		if err := g.Expr.Accept(visitor); err != nil {
+			return err
+		}
+	}
+	return visitor.VisitGroupByExpr(g)
+}
+
+// Keys returns the grouping keys, which are also what the arguments of grouping() refer to.
+// The keys repeated in the GROUPING SETS are returned once, and it returns nil for GROUP BY ALL.
+func (g *GroupByExpr) Keys() []Expr {
+	var items []Expr
+	switch expr := g.Expr.(type) {
+	case *ColumnExprList:
+		items = expr.Items
+	case *RollupExpr:
+		items = expr.Items.Items
+	case *CubeExpr:
+		items = expr.Items.Items
+	case *GroupingSetsExpr:
+		for _, set := range expr.Sets {
+			items = append(items, set.Items...)
+		}
+	}
+	seen := make(map[string]bool)
+	var keys []Expr
+	for _, item := range items {
+		key := item.String(0)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		keys = append(keys, item)
+	}
+	return keys
+}
+
+// RollupExpr is GROUP BY ROLLUP(a, b), which groups by (a, b), (a) and ()
+type RollupExpr struct {
+	RollupPos     Pos
+	RightParenPos Pos
+	Items         *ColumnExprList
+}
+
+func (r *RollupExpr) Pos() Pos {
+	return r.RollupPos
+}
+
+func (r *RollupExpr) End() Pos {
+	return r.RightParenPos
+}
+
+func (r *RollupExpr) String(level int) string {
+	return "ROLLUP(" + r.Items.String(level) + ")"
+}
+
+func (r *RollupExpr) Accept(visitor ASTVisitor) error {
+	visitor.enter(r)
+	defer visitor.leave(r)
+	if err := r.Items.Accept(visitor); err != nil {
+		return err
+	}
+	return visitor.VisitRollupExpr(r)
+}
+
+// CubeExpr is GROUP BY CUBE(a, b), which groups by every subset of the keys
+type CubeExpr struct {
+	CubePos       Pos
+	RightParenPos Pos
+	Items         *ColumnExprList
 }
 
-func (w *WhereExpr) Pos() Pos {
-	return w.WherePos
+func (c *CubeExpr) Pos() Pos {
+	return c.CubePos
 }
 
-func (w *WhereExpr) End() Pos {
-	return w.Expr.End()
+func (c *CubeExpr) End() Pos {
+	return c.RightParenPos
 }
 
-func (w *WhereExpr) String(level int) string {
-	var builder strings.Builder
-	builder.WriteString("WHERE")
-	builder.WriteString(NewLine(level + 1))
-	builder.WriteString(w.Expr.String(level))
-	return builder.String()
+func (c *CubeExpr) String(level int) string {
+	return "CUBE(" + c.Items.String(level) + ")"
 }
 
-func (w *WhereExpr) Accept(visitor ASTVisitor) error {
-	visitor.enter(w)
-	defer visitor.leave(w)
-	if err := w.Expr.Accept(visitor); err != nil {
+func (c *CubeExpr) Accept(visitor ASTVisitor) error {
+	visitor.enter(c)
+	defer visitor.leave(c)
+	if err := c.Items.Accept(visitor); err != nil {
 		return err
 	}
-	return visitor.VisitWhereExpr(w)
+	return visitor.VisitCubeExpr(c)
 }
 
-type PrewhereExpr struct {
-	PrewherePos Pos
-	Expr        Expr
+// GroupingSetsExpr is GROUP BY GROUPING SETS ((a, b), (a), ())
+type GroupingSetsExpr struct {
+	GroupingPos   Pos
+	RightParenPos Pos
+	Sets          []*GroupingSet
 }
 
-func (w *PrewhereExpr) Pos() Pos {
-	return w.PrewherePos
+func (g *GroupingSetsExpr) Pos() Pos {
+	return g.GroupingPos
 }
 
-func (w *PrewhereExpr) End() Pos {
-	return w.Expr.End()
+func (g *GroupingSetsExpr) End() Pos {
+	return g.RightParenPos
 }
 
-func (w *PrewhereExpr) String(level int) string {
-	return "PREWHERE " + w.Expr.String(level+1)
+func (g *GroupingSetsExpr) String(level int) string {
+	var builder strings.Builder
+	builder.WriteString("GROUPING SETS (")
+	for i, set := range g.Sets {
+		if i > 0 {
+			builder.WriteString(", ")
+		}
+		builder.WriteString(set.String(level))
+	}
+	builder.WriteByte(')')
+	return builder.String()
 }
 
-func (w *PrewhereExpr) Accept(visitor ASTVisitor) error {
-	visitor.enter(w)
-	defer visitor.leave(w)
-	if err := w.Expr.Accept(visitor); err != nil {
-		return err
+func (g *GroupingSetsExpr) Accept(visitor ASTVisitor) error {
+	visitor.enter(g)
+	defer visitor.leave(g)
+	for _, set := range g.Sets {
+		if err := set.Accept(visitor); err != nil {
+			return err
+		}
 	}
-	return visitor.VisitPrewhereExpr(w)
+	return visitor.VisitGroupingSetsExpr(g)
 }
 
-type GroupByExpr struct {
-	GroupByPos    Pos
-	AggregateType string
-	Expr          Expr
-	WithCube      bool
-	WithRollup    bool
-	WithTotals    bool
+// GroupingSet is one set of the keys in GROUPING SETS like (a, b) or the empty set (),
+// the set of a single key may be written without parentheses.
+type GroupingSet struct {
+	LeftParenPos  Pos
+	RightParenPos Pos
+	Items         []Expr
 }
 
-func (g *GroupByExpr) Pos() Pos {
-	return g.GroupByPos
+func (g *GroupingSet) Pos() Pos {
+	return g.LeftParenPos
 }
 
-func (g *GroupByExpr) End() Pos {
-	return g.Expr.End()
+func (g *GroupingSet) End() Pos {
+	return g.RightParenPos
 }
 
-func (g *GroupByExpr) String(level int) string {
+func (g *GroupingSet) String(level int) string {
 	var builder strings.Builder
-	builder.WriteString("GROUP BY ")
-	if g.AggregateType != "" {
-		builder.WriteString(g.AggregateType)
-		builder.WriteByte('(')
-	}
-	builder.WriteString(g.Expr.String(level))
-	if g.AggregateType != "" {
-		builder.WriteByte(')')
-	}
-	if g.WithCube {
-		builder.WriteString(" WITH CUBE")
-	}
-	if g.WithRollup {
-		builder.WriteString(" WITH ROLLUP")
-	}
-	if g.WithTotals {
-		builder.WriteString(" WITH TOTALS")
+	builder.WriteByte('(')
+	for i, item := range g.Items {
+		if i > 0 {
+			builder.WriteString(", ")
+		}
+		builder.WriteString(item.String(level))
 	}
+	builder.WriteByte(')')
 	return builder.String()
 }
 
-func (g *GroupByExpr) Accept(visitor ASTVisitor) error {
+func (g *GroupingSet) Accept(visitor ASTVisitor) error {
 	visitor.enter(g)
 	defer visitor.leave(g)
-	if err := g.Expr.Accept(visitor); err != nil {
-		return err
+	for _, item := range g.Items {
+		if err := item.Accept(visitor); err != nil {
+			return err
+		}
 	}
-	return visitor.VisitGroupByExpr(g)
+	return visitor.VisitGroupingSet(g)
 }
 
 type HavingExpr struct {
 	HavingPos Pos
 	Expr      Expr
+
+	Comments
 }
 
 func (h *HavingExpr) Pos() Pos {
@@ -4516,7 +5923,7 @@ func (h *HavingExpr) End() Pos {
 }
 
 func (h *HavingExpr) String(level int) string {
-	return "HAVING " + h.Expr.String(level)
+	return h.formatComments("HAVING "+h.Expr.String(level), level)
 }
 
 func (h *HavingExpr) Accept(visitor ASTVisitor) error {
@@ -4528,10 +5935,53 @@ func (h *HavingExpr) Accept(visitor ASTVisitor) error {
 	return visitor.VisitHavingExpr(h)
 }
 
+// QualifyExpr filters the rows by the window functions after they are computed, like
+// QUALIFY row_number() OVER (PARTITION BY u ORDER BY t DESC) = 1
+type QualifyExpr struct {
+	QualifyPos Pos
+	Expr       Expr
+
+	Comments
+}
+
+func (q *QualifyExpr) Pos() Pos {
+	return q.QualifyPos
+}
+
+func (q *QualifyExpr) End() Pos {
+	return q.Expr.End()
+}
+
+func (q *QualifyExpr) String(level int) string {
+	return q.formatComments("QUALIFY "+q.Expr.String(level), level)
+}
+
+func (q *QualifyExpr) Accept(visitor ASTVisitor) error {
+	visitor.enter(q)
+	defer visitor.leave(q)
+	if err := q.Expr.Accept(visitor); err != nil {
+		return err
+	}
+	return visitor.VisitQualifyExpr(q)
+}
+
+// LimitExpr is LIMIT n [OFFSET m] [WITH TIES], or the SQL-standard OFFSET m ROWS [FETCH FIRST n ROWS (ONLY | WITH TIES)]
+// whose LimitPos is the position of OFFSET. Limit is nil for OFFSET without FETCH, and both of Limit and
+// Offset may be negative or fractional.
 type LimitExpr struct {
-	LimitPos Pos
-	Limit    Expr
-	Offset   Expr
+	LimitPos     Pos
+	StatementEnd Pos
+	Limit        Expr
+	Offset       Expr
+	WithTies     bool
+	// OffsetRows is ROW or ROWS after the offset of OFFSET ... FETCH, empty if it's omitted
+	OffsetRows string
+	// Fetch is FIRST or NEXT of FETCH, empty without FETCH
+	Fetch string
+	// FetchRows is ROW or ROWS after the limit of FETCH
+	FetchRows string
+
+	Comments
 }
 
 func (l *LimitExpr) Pos() Pos {
@@ -4539,28 +5989,52 @@ func (l *LimitExpr) Pos() Pos {
 }
 
 func (l *LimitExpr) End() Pos {
-	if l.Offset != nil {
-		return l.Offset.End()
-	}
-	return l.Limit.End()
+	return l.StatementEnd
 }
 
 func (l *LimitExpr) String(level int) string {
 	var builder strings.Builder
+	if len(l.Fetch) > 0 || l.Limit == nil {
+		builder.WriteString("OFFSET ")
+		builder.WriteString(l.Offset.String(level))
+		if len(l.OffsetRows) > 0 {
+			builder.WriteByte(' ')
+			builder.WriteString(l.OffsetRows)
+		}
+		if len(l.Fetch) > 0 {
+			builder.WriteString(" FETCH ")
+			builder.WriteString(l.Fetch)
+			builder.WriteByte(' ')
+			builder.WriteString(l.Limit.String(level))
+			builder.WriteByte(' ')
+			builder.WriteString(l.FetchRows)
+			if l.WithTies {
+				builder.WriteString(" WITH TIES")
+			} else {
+				builder.WriteString(" ONLY")
+			}
+		}
+		return l.formatComments(builder.String(), level)
+	}
 	builder.WriteString("LIMIT ")
 	builder.WriteString(l.Limit.String(level))
 	if l.Offset != nil {
 		builder.WriteString(" OFFSET ")
 		builder.WriteString(l.Offset.String(level))
 	}
-	return builder.String()
+	if l.WithTies {
+		builder.WriteString(" WITH TIES")
+	}
+	return l.formatComments(builder.String(), level)
 }
 
 func (l *LimitExpr) Accept(visitor ASTVisitor) error {
 	visitor.enter(l)
 	defer visitor.leave(l)
-	if err := l.Limit.Accept(visitor); err != nil {
-		return err
+	if l.Limit != nil {
+		if err := l.Limit.Accept(visitor); err != nil {
+			return err
+		}
 	}
 	if l.Offset != nil {
 		if err := l.Offset.Accept(visitor); err != nil {
@@ -4570,9 +6044,12 @@ func (l *LimitExpr) Accept(visitor ASTVisitor) error {
 	return visitor.VisitLimitExpr(l)
 }
 
+// LimitByExpr is LIMIT n [OFFSET m] BY columnExprList
 type LimitByExpr struct {
 	Limit  *LimitExpr
 	ByExpr *ColumnExprList
+
+	Comments
 }
 
 func (l *LimitByExpr) Pos() Pos {
@@ -4583,25 +6060,17 @@ func (l *LimitByExpr) End() Pos {
 	if l.ByExpr != nil {
 		return l.ByExpr.End()
 	}
-	if l.Limit != nil {
-		return l.Limit.End()
-	}
 	return l.Limit.End()
 }
 
 func (l *LimitByExpr) String(level int) string {
 	var builder strings.Builder
-	builder.WriteString("LIMIT ")
 	builder.WriteString(l.Limit.String(level))
-	if l.Limit != nil {
-		builder.WriteString(" OFFSET ")
-		builder.WriteString(l.Limit.String(level))
-	}
 	if l.ByExpr != nil {
 		builder.WriteString(" BY ")
 		builder.WriteString(l.ByExpr.String(level))
 	}
-	return builder.String()
+	return l.formatComments(builder.String(), level)
 }
 
 func (l *LimitByExpr) Accept(visitor ASTVisitor) error {
@@ -4682,6 +6151,8 @@ type WindowExpr struct {
 	WindowPos Pos
 	Name      *Ident
 	AsPos     Pos
+
+	Comments
 }
 
 func (w *WindowExpr) Pos() Pos {
@@ -4698,7 +6169,7 @@ func (w *WindowExpr) String(level int) string {
 	builder.WriteString(w.Name.String(level))
 	builder.WriteString(" ")
 	builder.WriteString(w.WindowConditionExpr.String(level))
-	return builder.String()
+	return w.formatComments(builder.String(), level)
 }
 
 func (w *WindowExpr) Accept(visitor ASTVisitor) error {
@@ -4891,6 +6362,8 @@ type ArrayJoinExpr struct {
 	ArrayPos Pos
 	Type     string
 	Expr     Expr
+
+	Comments
 }
 
 func (a *ArrayJoinExpr) Pos() Pos {
@@ -4902,7 +6375,7 @@ func (a *ArrayJoinExpr) End() Pos {
 }
 
 func (a *ArrayJoinExpr) String(level int) string {
-	return a.Type + " ARRAY JOIN " + a.Expr.String(level)
+	return a.formatComments(a.Type+" ARRAY JOIN "+a.Expr.String(level), level)
 }
 
 func (a *ArrayJoinExpr) Accept(visitor ASTVisitor) error {
@@ -4928,13 +6401,18 @@ type SelectQuery struct {
 	GroupBy       *GroupByExpr
 	WithTotal     bool
 	Having        *HavingExpr
+	Qualify       *QualifyExpr
 	OrderBy       *OrderByListExpr
 	LimitBy       *LimitByExpr
 	Limit         *LimitExpr
 	Settings      *SettingsExprList
-	UnionAll      *SelectQuery
-	UnionDistinct *SelectQuery
-	Except        *SelectQuery
+	// SetOperation is set if the query combines other queries like A UNION ALL B, the other
+	// clauses are empty in this case since they belong to the combined queries.
+	SetOperation *SetOperationExpr
+	// HasParen is set if the query is a parenthesized operand of a set operation like (SELECT 1 LIMIT 1) UNION ALL SELECT 2
+	HasParen bool `json:",omitempty"`
+
+	Comments
 }
 
 func (s *SelectQuery) Pos() Pos {
@@ -4947,6 +6425,9 @@ func (s *SelectQuery) End() Pos {
 
 func (s *SelectQuery) String(level int) string { // nolint: funlen
 	var builder strings.Builder
+	if s.SetOperation != nil {
+		return s.formatComments(s.SetOperation.String(level), level)
+	}
 	if s.With != nil {
 		builder.WriteString("WITH")
 		for i, cte := range s.With.CTEs {
@@ -4964,72 +6445,70 @@ func (s *SelectQuery) String(level int) string { // nolint: funlen
 		builder.WriteString(s.Top.String(level))
 		builder.WriteString(" ")
 	}
-	columns := s.SelectColumns.Items
-	for i, column := range columns {
+	columns := s.SelectColumns
+	endsWithLineComment := false
+	for i, column := range columns.Items {
 		builder.WriteString(NewLine(level + 1))
-		builder.WriteString(column.String(level))
-		if i != len(columns)-1 {
-			builder.WriteByte(',')
+		separator := ","
+		if i == len(columns.Items)-1 {
+			separator = ""
 		}
+		var formatted string
+		formatted, endsWithLineComment = columns.itemComments(i).formatListItem(column.String(level), separator, level+1)
+		builder.WriteString(formatted)
+	}
+	if endsWithLineComment {
+		writeNewLine(&builder, level)
 	}
 	if s.From != nil {
-		builder.WriteString(NewLine(level))
+		writeNewLine(&builder, level)
 		builder.WriteString(s.From.String(level))
 	}
 	if s.ArrayJoin != nil {
-		builder.WriteString(NewLine(level))
+		writeNewLine(&builder, level)
 		builder.WriteString(s.ArrayJoin.String(level))
 	}
 	if s.Window != nil {
-		builder.WriteString(NewLine(level))
+		writeNewLine(&builder, level)
 		builder.WriteString(s.Window.String(level))
 	}
 	if s.Prewhere != nil {
-		builder.WriteString(NewLine(level))
+		writeNewLine(&builder, level)
 		builder.WriteString(s.Prewhere.String(level))
 	}
 	if s.Where != nil {
-		builder.WriteString(NewLine(level))
+		writeNewLine(&builder, level)
 		builder.WriteString(s.Where.String(level))
 	}
 	if s.GroupBy != nil {
-		builder.WriteString(NewLine(level))
+		writeNewLine(&builder, level)
 		builder.WriteString(s.GroupBy.String(level))
 	}
 	if s.Having != nil {
-		builder.WriteString(NewLine(level))
+		writeNewLine(&builder, level)
 		builder.WriteString(s.Having.String(level))
 	}
+	if s.Qualify != nil {
+		writeNewLine(&builder, level)
+		builder.WriteString(s.Qualify.String(level))
+	}
 	if s.OrderBy != nil {
-		builder.WriteString(NewLine(level))
+		writeNewLine(&builder, level)
 		builder.WriteString(s.OrderBy.String(level))
 	}
 	if s.LimitBy != nil {
-		builder.WriteString(NewLine(level))
+		writeNewLine(&builder, level)
 		builder.WriteString(s.LimitBy.String(level))
 	}
 	if s.Limit != nil {
-		builder.WriteString(NewLine(level))
+		writeNewLine(&builder, level)
 		builder.WriteString(s.Limit.String(level))
 	}
 	if s.Settings != nil {
-		builder.WriteString(NewLine(level))
+		writeNewLine(&builder, level)
 		builder.WriteString(s.Settings.String(level))
 	}
-	if s.UnionAll != nil {
-		builder.WriteString(NewLine(level))
-		builder.WriteString(" UNION ALL ")
-		builder.WriteString(s.UnionAll.String(level))
-	} else if s.UnionDistinct != nil {
-		builder.WriteString(NewLine(level))
-		builder.WriteString(" UNION DISTINCT ")
-		builder.WriteString(s.UnionDistinct.String(level))
-	} else if s.Except != nil {
-		builder.WriteString(NewLine(level))
-		builder.WriteString(" EXCEPT ")
-		builder.WriteString(s.Except.String(level))
-	}
-	return builder.String()
+	return s.formatComments(builder.String(), level)
 }
 
 func (s *SelectQuery) Accept(visitor ASTVisitor) error {
@@ -5085,6 +6564,11 @@ func (s *SelectQuery) Accept(visitor ASTVisitor) error {
 			return err
 		}
 	}
+	if s.Qualify != nil {
+		if err := s.Qualify.Accept(visitor); err != nil {
+			return err
+		}
+	}
 	if s.OrderBy != nil {
 		if err := s.OrderBy.Accept(visitor); err != nil {
 			return err
@@ -5105,22 +6589,80 @@ func (s *SelectQuery) Accept(visitor ASTVisitor) error {
 			return err
 		}
 	}
-	if s.UnionAll != nil {
-		if err := s.UnionAll.Accept(visitor); err != nil {
+	if s.SetOperation != nil {
+		if err := s.SetOperation.Accept(visitor); err != nil {
 			return err
 		}
 	}
-	if s.UnionDistinct != nil {
-		if err := s.UnionDistinct.Accept(visitor); err != nil {
-			return err
-		}
+	return visitor.VisitSelectQuery(s)
+}
+
+// SetOperationExpr combines the results of two queries like A UNION ALL B, INTERSECT binds tighter
+// than UNION and EXCEPT, which are left-associative.
+type SetOperationExpr struct {
+	Left        Expr // *SelectQuery or *SetOperationExpr
+	OperatorPos Pos
+	Operator    string // UNION, EXCEPT or INTERSECT
+	Modifier    string // ALL, DISTINCT or empty
+	Right       Expr   // *SelectQuery or *SetOperationExpr
+}
+
+func (s *SetOperationExpr) Pos() Pos {
+	return s.Left.Pos()
+}
+
+func (s *SetOperationExpr) End() Pos {
+	return s.Right.End()
+}
+
+func (s *SetOperationExpr) String(level int) string {
+	precedence := setOperationPrecedences[s.Operator]
+	var builder strings.Builder
+	builder.WriteString(formatSetOperand(s.Left, precedence, level))
+	builder.WriteString(NewLine(level))
+	builder.WriteByte(' ')
+	builder.WriteString(s.Operator)
+	if s.Modifier != "" {
+		builder.WriteByte(' ')
+		builder.WriteString(s.Modifier)
 	}
-	if s.Except != nil {
-		if err := s.Except.Accept(visitor); err != nil {
-			return err
+	builder.WriteByte(' ')
+	builder.WriteString(formatSetOperand(s.Right, precedence+1, level))
+	return builder.String()
+}
+
+// formatSetOperand parenthesizes the operand which is a set operation binding looser than minPrecedence,
+// or a parenthesized query whose clauses would otherwise be taken for those of the whole set operation.
+func formatSetOperand(operand Expr, minPrecedence int, level int) string {
+	switch operand := operand.(type) {
+	case *SetOperationExpr:
+		if setOperationPrecedences[operand.Operator] < minPrecedence {
+			return "(" + operand.String(level+1) + ")"
+		}
+	case *SelectQuery:
+		if operand.HasParen && operand.hasSetOperationClauses() {
+			return "(" + operand.String(level+1) + ")"
 		}
 	}
-	return visitor.VisitSelectQuery(s)
+	return operand.String(level)
+}
+
+// hasSetOperationClauses reports whether the query has the clauses which are able to apply to a whole
+// set operation, the WITH clause is shared by all queries and the last query's ones end the statement.
+func (s *SelectQuery) hasSetOperationClauses() bool {
+	return s.With != nil || s.OrderBy != nil || s.LimitBy != nil || s.Limit != nil || s.Settings != nil
+}
+
+func (s *SetOperationExpr) Accept(visitor ASTVisitor) error {
+	visitor.enter(s)
+	defer visitor.leave(s)
+	if err := s.Left.Accept(visitor); err != nil {
+		return err
+	}
+	if err := s.Right.Accept(visitor); err != nil {
+		return err
+	}
+	return visitor.VisitSetOperationExpr(s)
 }
 
 type SubQueryExpr struct {
@@ -5170,7 +6712,7 @@ func (n *NotExpr) End() Pos {
 }
 
 func (n *NotExpr) String(level int) string {
-	return "NOT " + n.Expr.String(level+1)
+	return "NOT " + formatPrefixOperand(n.Expr, precedenceNot+1, level+1)
 }
 
 func (n *NotExpr) Accept(visitor ASTVisitor) error {
@@ -5274,6 +6816,8 @@ type DropDatabase struct {
 	Name         *Ident
 	IfExists     bool
 	OnCluster    *OnClusterExpr
+
+	Comments
 }
 
 func (d *DropDatabase) Pos() Pos {
@@ -5299,7 +6843,7 @@ func (d *DropDatabase) String(level int) string {
 		builder.WriteString(NewLine(level + 1))
 		builder.WriteString(d.OnCluster.String(level))
 	}
-	return builder.String()
+	return d.formatComments(builder.String(), level)
 }
 
 func (d *DropDatabase) Accept(visitor ASTVisitor) error {
@@ -5326,6 +6870,8 @@ type DropStmt struct {
 	OnCluster   *OnClusterExpr
 	IsTemporary bool
 	Modifier    string
+
+	Comments
 }
 
 func (d *DropStmt) Pos() Pos {
@@ -5358,7 +6904,7 @@ func (d *DropStmt) String(level int) string {
 	if len(d.Modifier) != 0 {
 		builder.WriteString(" " + d.Modifier)
 	}
-	return builder.String()
+	return d.formatComments(builder.String(), level)
 }
 
 func (d *DropStmt) Accept(visitor ASTVisitor) error {
@@ -5384,6 +6930,8 @@ type DropUserOrRole struct {
 	IfExists     bool
 	Modifier     string
 	From         *Ident
+
+	Comments
 }
 
 func (d *DropUserOrRole) Pos() Pos {
@@ -5417,7 +6965,7 @@ func (d *DropUserOrRole) String(level int) string {
 		builder.WriteString(" FROM ")
 		builder.WriteString(d.From.String(level))
 	}
-	return builder.String()
+	return d.formatComments(builder.String(), level)
 }
 
 func (d *DropUserOrRole) Accept(visitor ASTVisitor) error {
@@ -5440,6 +6988,8 @@ type UseExpr struct {
 	UsePos       Pos
 	StatementEnd Pos
 	Database     *Ident
+
+	Comments
 }
 
 func (u *UseExpr) Pos() Pos {
@@ -5451,7 +7001,7 @@ func (u *UseExpr) End() Pos {
 }
 
 func (u *UseExpr) String(level int) string {
-	return "USE " + u.Database.String(level+1)
+	return u.formatComments("USE "+u.Database.String(level+1), level)
 }
 
 func (u *UseExpr) Accept(visitor ASTVisitor) error {
@@ -5506,6 +7056,8 @@ func (c *CTEExpr) Accept(visitor ASTVisitor) error {
 type SetExpr struct {
 	SetPos   Pos
 	Settings *SettingsExprList
+
+	Comments
 }
 
 func (s *SetExpr) Pos() Pos {
@@ -5525,7 +7077,7 @@ func (s *SetExpr) String(level int) string {
 		}
 		builder.WriteString(item.String(level))
 	}
-	return builder.String()
+	return s.formatComments(builder.String(), level)
 }
 
 func (s *SetExpr) Accept(visitor ASTVisitor) error {
@@ -5571,6 +7123,8 @@ type OptimizeExpr struct {
 	Partition    *PartitionExpr
 	HasFinal     bool
 	Deduplicate  *DeduplicateExpr
+
+	Comments
 }
 
 func (o *OptimizeExpr) Pos() Pos {
@@ -5599,7 +7153,7 @@ func (o *OptimizeExpr) String(level int) string {
 	if o.Deduplicate != nil {
 		builder.WriteString(o.Deduplicate.String(level))
 	}
-	return builder.String()
+	return o.formatComments(builder.String(), level)
 }
 
 func (o *OptimizeExpr) Accept(visitor ASTVisitor) error {
@@ -5629,7 +7183,7 @@ func (o *OptimizeExpr) Accept(visitor ASTVisitor) error {
 type DeduplicateExpr struct {
 	DeduplicatePos Pos
 	By             *ColumnExprList
-	Except         *ColumnExprList
+	Except         *ColumnExprList // EXCEPT after * or COLUMNS(...) is parsed as the ExceptTransformer of the BY item
 }
 
 func (d *DeduplicateExpr) Pos() Pos {
@@ -5678,6 +7232,8 @@ func (d *DeduplicateExpr) Accept(visitor ASTVisitor) error {
 type SystemExpr struct {
 	SystemPos Pos
 	Expr      Expr
+
+	Comments
 }
 
 func (s *SystemExpr) Pos() Pos {
@@ -5689,7 +7245,7 @@ func (s *SystemExpr) End() Pos {
 }
 
 func (s *SystemExpr) String(level int) string {
-	return "SYSTEM " + s.Expr.String(level)
+	return s.formatComments("SYSTEM "+s.Expr.String(level), level)
 }
 
 func (s *SystemExpr) Accept(visitor ASTVisitor) error {
@@ -5874,6 +7430,8 @@ type TruncateTable struct {
 	IfExists     bool
 	Name         *TableIdentifier
 	OnCluster    *OnClusterExpr
+
+	Comments
 }
 
 func (t *TruncateTable) Pos() Pos {
@@ -5903,7 +7461,7 @@ func (t *TruncateTable) String(level int) string {
 		builder.WriteString(NewLine(level))
 		builder.WriteString(t.OnCluster.String(level))
 	}
-	return builder.String()
+	return t.formatComments(builder.String(), level)
 }
 
 func (t *TruncateTable) Accept(visitor ASTVisitor) error {
@@ -5967,6 +7525,8 @@ type DeleteFromExpr struct {
 	Table     *TableIdentifier
 	OnCluster *OnClusterExpr
 	WhereExpr Expr
+
+	Comments
 }
 
 func (d *DeleteFromExpr) Pos() Pos {
@@ -5990,7 +7550,7 @@ func (d *DeleteFromExpr) String(level int) string {
 		builder.WriteString("WHERE ")
 		builder.WriteString(d.WhereExpr.String(level))
 	}
-	return builder.String()
+	return d.formatComments(builder.String(), level)
 }
 
 func (d *DeleteFromExpr) Accept(visitor ASTVisitor) error {
@@ -6095,6 +7655,8 @@ type InsertExpr struct {
 	ColumnNames *ColumnNamesExpr
 	Values      []*ValuesExpr
 	SelectExpr  *SelectQuery
+
+	Comments
 }
 
 func (i *InsertExpr) Pos() Pos {
@@ -6134,7 +7696,7 @@ func (i *InsertExpr) String(level int) string {
 			builder.WriteString(value.String(level))
 		}
 	}
-	return builder.String()
+	return i.formatComments(builder.String(), level)
 }
 
 func (i *InsertExpr) Accept(visitor ASTVisitor) error {
@@ -6170,6 +7732,8 @@ type CheckExpr struct {
 	CheckPos  Pos
 	Table     *TableIdentifier
 	Partition *PartitionExpr
+
+	Comments
 }
 
 func (c *CheckExpr) Pos() Pos {
@@ -6188,7 +7752,7 @@ func (c *CheckExpr) String(level int) string {
 	if c.Partition != nil {
 		builder.WriteString(c.Partition.String(level))
 	}
-	return builder.String()
+	return c.formatComments(builder.String(), level)
 }
 
 func (c *CheckExpr) Accept(visitor ASTVisitor) error {
@@ -6220,7 +7784,12 @@ func (n *UnaryExpr) End() Pos {
 }
 
 func (n *UnaryExpr) String(level int) string {
-	return "-" + n.Expr.String(level+1)
+	operand := formatPrefixOperand(n.Expr, precedencePostfix, level+1)
+	if strings.HasPrefix(operand, "-") || strings.HasPrefix(operand, "+") {
+		// avoid -- which starts a comment
+		return string(n.Kind) + " " + operand
+	}
+	return string(n.Kind) + operand
 }
 
 func (n *UnaryExpr) Accept(visitor ASTVisitor) error {
@@ -6239,6 +7808,8 @@ type RenameStmt struct {
 	RenameTarget   string
 	TargetPairList []*TargetPair
 	OnCluster      *OnClusterExpr
+
+	Comments
 }
 
 func (r *RenameStmt) Pos() Pos {
@@ -6268,7 +7839,7 @@ func (r *RenameStmt) String(level int) string {
 		builder.WriteString(NewLine(level))
 		builder.WriteString(r.OnCluster.String(level))
 	}
-	return builder.String()
+	return r.formatComments(builder.String(), level)
 }
 
 func (r *RenameStmt) Accept(visitor ASTVisitor) error {
@@ -6311,6 +7882,8 @@ type ExplainExpr struct {
 	ExplainPos Pos
 	Type       string
 	Statement  Expr
+
+	Comments
 }
 
 func (e *ExplainExpr) Pos() Pos {
@@ -6327,7 +7900,7 @@ func (e *ExplainExpr) String(level int) string {
 	builder.WriteString(e.Type)
 	builder.WriteByte(' ')
 	builder.WriteString(e.Statement.String(level))
-	return builder.String()
+	return e.formatComments(builder.String(), level)
 }
 
 func (e *ExplainExpr) Accept(visitor ASTVisitor) error {
@@ -6387,6 +7960,8 @@ type GrantPrivilegeExpr struct {
 	On           *TableIdentifier
 	To           []*Ident
 	WithOptions  []string
+
+	Comments
 }
 
 func (g *GrantPrivilegeExpr) Pos() Pos {
@@ -6427,7 +8002,7 @@ func (g *GrantPrivilegeExpr) String(level int) string {
 		builder.WriteString(" WITH " + option + " OPTION")
 	}
 
-	return builder.String()
+	return g.formatComments(builder.String(), level)
 }
 
 func (g *GrantPrivilegeExpr) Accept(visitor ASTVisitor) error {
@@ -6453,3 +8028,108 @@ func (g *GrantPrivilegeExpr) Accept(visitor ASTVisitor) error {
 	}
 	return visitor.VisitGrantPrivilegeExpr(g)
 }
+
+// Comment is a `-- ...` or `/* ... */` comment kept by a parser that retains comments.
+type Comment struct {
+	CommentPos Pos
+	CommentEnd Pos
+	Text       string // the comment including its markers
+}
+
+func (c *Comment) Pos() Pos {
+	return c.CommentPos
+}
+
+func (c *Comment) End() Pos {
+	return c.CommentEnd
+}
+
+func (c *Comment) String(int) string {
+	return c.Text
+}
+
+func (c *Comment) IsLineComment() bool {
+	return strings.HasPrefix(c.Text, "--")
+}
+
+type Comments struct {
+	LeadingComments  []*Comment `json:",omitempty"`
+	TrailingComments []*Comment `json:",omitempty"`
+}
+
+func (c *Comments) comments() *Comments {
+	return c
+}
+
+// formatComments prints the leading comments before and the trailing comments after the formatted node.
+// The comments keep their styles, so a line break follows the trailing line comment.
+func (c *Comments) formatComments(formatted string, level int) string {
+	if len(c.LeadingComments) == 0 && len(c.TrailingComments) == 0 {
+		return formatted
+	}
+	var builder strings.Builder
+	if len(c.LeadingComments) > 0 && strings.HasPrefix(formatted, NewLine(level)) {
+		// keep the line break which the node starts with in front of the comments
+		builder.WriteString(NewLine(level))
+		formatted = formatted[len(NewLine(level)):]
+	}
+	builder.WriteString(c.leadingString(level))
+	builder.WriteString(formatted)
+	trailing, endsWithLineComment := c.trailingString(level)
+	builder.WriteString(trailing)
+	if endsWithLineComment {
+		builder.WriteString(NewLine(level))
+	}
+	return builder.String()
+}
+
+func (c *Comments) leadingString(level int) string {
+	var builder strings.Builder
+	for _, comment := range c.LeadingComments {
+		builder.WriteString(comment.String(level))
+		builder.WriteString(NewLine(level))
+	}
+	return builder.String()
+}
+
+// trailingString returns the trailing comments and whether the last one is a line comment, which
+// must be followed by a line break.
+func (c *Comments) trailingString(level int) (string, bool) {
+	var builder strings.Builder
+	for i, comment := range c.TrailingComments {
+		// the comment after a line comment starts the next line
+		if i == 0 || !c.TrailingComments[i-1].IsLineComment() {
+			builder.WriteByte(' ')
+		}
+		builder.WriteString(comment.String(level))
+		if comment.IsLineComment() && i != len(c.TrailingComments)-1 {
+			builder.WriteString(NewLine(level))
+		}
+	}
+	n := len(c.TrailingComments)
+	return builder.String(), n > 0 && c.TrailingComments[n-1].IsLineComment()
+}
+
+// formatListItem prints the item of a list with the comments, formatted doesn't include the comments.
+// The separator goes before the trailing comments so that a line comment doesn't swallow it, and
+// it reports whether the item ends with a line comment, after which the list must break the line.
+func (c *Comments) formatListItem(formatted, separator string, level int) (string, bool) {
+	if c == nil {
+		return formatted + separator, false
+	}
+	trailing, endsWithLineComment := c.trailingString(level)
+	return c.leadingString(level) + formatted + separator + trailing, endsWithLineComment
+}
+
+// formatCommentedListItem is formatListItem of the node which prints its own comments at commentLevel.
+func formatCommentedListItem(item commentable, separator string, level, commentLevel int) (string, bool) {
+	formatted := item.String(level)
+	trailing, endsWithLineComment := item.comments().trailingString(commentLevel)
+	if len(trailing) == 0 {
+		return formatted + separator, false
+	}
+	if endsWithLineComment {
+		formatted = strings.TrimSuffix(formatted, NewLine(commentLevel))
+	}
+	return strings.TrimSuffix(formatted, trailing) + separator + trailing, endsWithLineComment
+}
@@ -0,0 +1,146 @@
+package parser
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func parseNumberLiteral(t *testing.T, literal string) *NumberLiteral {
+	parser := NewParser(literal)
+	_ = parser.lexer.consumeToken()
+	expr, err := parser.parseColumnExpr(parser.Pos())
+	require.NoError(t, err)
+	number, ok := expr.(*NumberLiteral)
+	require.True(t, ok, "%s is not a number literal", literal)
+	return number
+}
+
+func TestNumberLiteral_Int64(t *testing.T) {
+	numbers := map[string]int64{
+		"123":                  123,
+		"-123":                 -123,
+		"1_000_000":            1000000,
+		"0x1F":                 31,
+		"0X1f":                 31,
+		"0b1010":               10,
+		"-0b1_0":               -2,
+		"123e3":                123000,
+		"1.5e3":                1500,
+		"0x1p4":                16,
+		"9223372036854775807":  math.MaxInt64,
+		"-9223372036854775808": math.MinInt64,
+	}
+	for literal, expected := range numbers {
+		value, err := parseNumberLiteral(t, literal).Int64()
+		require.NoError(t, err, literal)
+		require.Equal(t, expected, value, literal)
+	}
+
+	_, err := parseNumberLiteral(t, "9223372036854775808").Int64()
+	require.True(t, errors.Is(err, strconv.ErrRange))
+	_, err = parseNumberLiteral(t, "1.5").Int64()
+	require.True(t, errors.Is(err, strconv.ErrSyntax))
+	_, err = parseNumberLiteral(t, "inf").Int64()
+	require.Error(t, err)
+}
+
+func TestNumberLiteral_Uint64(t *testing.T) {
+	value, err := parseNumberLiteral(t, "18446744073709551615").Uint64()
+	require.NoError(t, err)
+	require.Equal(t, uint64(math.MaxUint64), value)
+
+	_, err = parseNumberLiteral(t, "18446744073709551616").Uint64()
+	require.True(t, errors.Is(err, strconv.ErrRange))
+	_, err = parseNumberLiteral(t, "-1").Uint64()
+	require.True(t, errors.Is(err, strconv.ErrRange))
+}
+
+func TestNumberLiteral_BigInt(t *testing.T) {
+	value, err := parseNumberLiteral(t, "123_456_789_012_345_678_901_234_567_890").BigInt()
+	require.NoError(t, err)
+	expected, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	require.Equal(t, 0, expected.Cmp(value))
+
+	_, err = parseNumberLiteral(t, "0.5").BigInt()
+	require.Error(t, err)
+}
+
+func TestNumberLiteral_Float64(t *testing.T) {
+	numbers := map[string]float64{
+		"1.5":       1.5,
+		".5":        0.5,
+		"-2.5e-3":   -0.0025,
+		"1_000.5":   1000.5,
+		"0x1.8p3":   12,
+		"0x1p-2":    0.25,
+		"0b11":      3,
+		"123":       123,
+		"inf":       math.Inf(1),
+		"Infinity":  math.Inf(1),
+		"1e308":     1e308,
+		"-1.5E+10":  -1.5e10,
+		"0xA.8":     10.5,
+		"123456789": 123456789,
+	}
+	for literal, expected := range numbers {
+		value, err := parseNumberLiteral(t, literal).Float64()
+		require.NoError(t, err, literal)
+		require.Equal(t, expected, value, literal)
+	}
+
+	value, err := parseNumberLiteral(t, "nan").Float64()
+	require.NoError(t, err)
+	require.True(t, math.IsNaN(value))
+
+	_, err = parseNumberLiteral(t, "1e309").Float64()
+	require.True(t, errors.Is(err, strconv.ErrRange))
+
+	value, err = parseNumberLiteral(t, "1e10000000").Float64()
+	require.True(t, errors.Is(err, strconv.ErrRange))
+	require.True(t, math.IsInf(value, 1))
+
+	value, err = parseNumberLiteral(t, "1e-10000000").Float64()
+	require.NoError(t, err)
+	require.Equal(t, 0.0, value)
+
+	value, err = parseNumberLiteral(t, "0x1F").Float64()
+	require.NoError(t, err)
+	require.Equal(t, 31.0, value)
+}
+
+func TestIdent_String(t *testing.T) {
+	idents := map[*Ident]string{
+		{Name: "user_id"}:                           "user_id",
+		{Name: "events"}:                            "`events`",
+		{Name: "select"}:                            "`select`",
+		{Name: "Year"}:                              "`Year`",
+		{Name: "a`b"}:                               "`a\\`b`",
+		{Name: `a\b`}:                               "`a\\\\b`",
+		{Name: "year", QuoteType: Unquoted}:         "year",
+		{Name: `a"b`, QuoteType: DoubleQuote}:       `"a\"b"`,
+		{Name: "order total", QuoteType: BackTicks}: "`order total`",
+	}
+	for ident, expected := range idents {
+		require.Equal(t, expected, ident.String(0))
+		if ident.QuoteType == Unquoted {
+			// the parsed keyword is kept bare where it was valid
+			continue
+		}
+
+		// the identifier is parsed back to the same name
+		expr, err := NewParser(expected).ParseExpr()
+		require.NoError(t, err, expected)
+		require.Equal(t, ident.Name, expr.(*Ident).Name, expected)
+	}
+
+	for sql, name := range map[string]string{"`a``b`": "a`b", `"a""b"`: `a"b`, "`a\\`b`": "a`b"} {
+		expr, err := NewParser(sql).ParseExpr()
+		require.NoError(t, err, sql)
+		require.Equal(t, name, expr.(*Ident).Name, sql)
+	}
+}
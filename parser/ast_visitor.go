@@ -4,6 +4,17 @@ type ASTVisitor interface {
 	VisitOperationExpr(expr *OperationExpr) error
 	VisitTernaryExpr(expr *TernaryExpr) error
 	VisitBinaryExpr(expr *BinaryExpr) error
+	VisitLambdaExpr(expr *LambdaExpr) error
+	VisitBetweenExpr(expr *BetweenExpr) error
+	VisitSubQuery(expr *SubQuery) error
+	VisitInExpr(expr *InExpr) error
+	VisitExistsExpr(expr *ExistsExpr) error
+	VisitQuantifiedComparisonExpr(expr *QuantifiedComparisonExpr) error
+	VisitColumnsMatcher(expr *ColumnsMatcher) error
+	VisitColumnTransformerExpr(expr *ColumnTransformerExpr) error
+	VisitApplyTransformer(expr *ApplyTransformer) error
+	VisitExceptTransformer(expr *ExceptTransformer) error
+	VisitReplaceTransformer(expr *ReplaceTransformer) error
 	VisitAlterTable(expr *AlterTable) error
 	VisitAlterTableAttachPartition(expr *AlterTableAttachPartition) error
 	VisitAlterTableDetachPartition(expr *AlterTableDetachPartition) error
@@ -32,6 +43,7 @@ type ASTVisitor interface {
 	VisitTableIndex(expr *TableIndex) error
 	VisitIdent(expr *Ident) error
 	VisitUUID(expr *UUID) error
+	VisitQueryParameterExpr(expr *QueryParameterExpr) error
 	VisitCreateDatabase(expr *CreateDatabase) error
 	VisitCreateTable(expr *CreateTable) error
 	VisitCreateMaterializedView(expr *CreateMaterializedView) error
@@ -63,12 +75,21 @@ type ASTVisitor interface {
 	VisitTTLExprList(expr *TTLExprList) error
 	VisitOrderByExpr(expr *OrderByExpr) error
 	VisitOrderByListExpr(expr *OrderByListExpr) error
+	VisitWithFillExpr(expr *WithFillExpr) error
+	VisitInterpolateExpr(expr *InterpolateExpr) error
+	VisitInterpolateItem(expr *InterpolateItem) error
 	VisitSettingsExpr(expr *SettingsExpr) error
 	VisitSettingsExprList(expr *SettingsExprList) error
 	VisitParamExprList(expr *ParamExprList) error
 	VisitArrayParamList(expr *ArrayParamList) error
+	VisitTupleLiteral(expr *TupleLiteral) error
+	VisitMapLiteral(expr *MapLiteral) error
 	VisitObjectParams(expr *ObjectParams) error
+	VisitIndexExpr(expr *IndexExpr) error
+	VisitTupleElementExpr(expr *TupleElementExpr) error
 	VisitFunctionExpr(expr *FunctionExpr) error
+	VisitFilterExpr(expr *FilterExpr) error
+	VisitJSONPathExpr(expr *JSONPathExpr) error
 	VisitWindowFunctionExpr(expr *WindowFunctionExpr) error
 	VisitColumn(expr *Column) error
 	VisitScalarTypeExpr(expr *ScalarTypeExpr) error
@@ -107,7 +128,12 @@ type ASTVisitor interface {
 	VisitWhereExpr(expr *WhereExpr) error
 	VisitPrewhereExpr(expr *PrewhereExpr) error
 	VisitGroupByExpr(expr *GroupByExpr) error
+	VisitRollupExpr(expr *RollupExpr) error
+	VisitCubeExpr(expr *CubeExpr) error
+	VisitGroupingSetsExpr(expr *GroupingSetsExpr) error
+	VisitGroupingSet(expr *GroupingSet) error
 	VisitHavingExpr(expr *HavingExpr) error
+	VisitQualifyExpr(expr *QualifyExpr) error
 	VisitLimitExpr(expr *LimitExpr) error
 	VisitLimitByExpr(expr *LimitByExpr) error
 	VisitWindowConditionExpr(expr *WindowConditionExpr) error
@@ -120,6 +146,7 @@ type ASTVisitor interface {
 	VisitWindowFrameNumber(expr *WindowFrameNumber) error
 	VisitArrayJoinExpr(expr *ArrayJoinExpr) error
 	VisitSelectQuery(expr *SelectQuery) error
+	VisitSetOperationExpr(expr *SetOperationExpr) error
 	VisitSubQueryExpr(expr *SubQueryExpr) error
 	VisitNotExpr(expr *NotExpr) error
 	VisitNegateExpr(expr *NegateExpr) error
@@ -191,6 +218,160 @@ func (v *DefaultASTVisitor) VisitJoinTableExpr(expr *JoinTableExpr) error {
 	return nil
 }
 
+func (v *DefaultASTVisitor) VisitLambdaExpr(expr *LambdaExpr) error {
+	if v.Visit != nil {
+		return v.Visit(expr)
+	}
+	return nil
+}
+
+func (v *DefaultASTVisitor) VisitBetweenExpr(expr *BetweenExpr) error {
+	if v.Visit != nil {
+		return v.Visit(expr)
+	}
+	return nil
+}
+
+func (v *DefaultASTVisitor) VisitSubQuery(expr *SubQuery) error {
+	if v.Visit != nil {
+		return v.Visit(expr)
+	}
+	return nil
+}
+
+func (v *DefaultASTVisitor) VisitInExpr(expr *InExpr) error {
+	if v.Visit != nil {
+		return v.Visit(expr)
+	}
+	return nil
+}
+
+func (v *DefaultASTVisitor) VisitExistsExpr(expr *ExistsExpr) error {
+	if v.Visit != nil {
+		return v.Visit(expr)
+	}
+	return nil
+}
+
+func (v *DefaultASTVisitor) VisitQuantifiedComparisonExpr(expr *QuantifiedComparisonExpr) error {
+	if v.Visit != nil {
+		return v.Visit(expr)
+	}
+	return nil
+}
+
+func (v *DefaultASTVisitor) VisitColumnsMatcher(expr *ColumnsMatcher) error {
+	if v.Visit != nil {
+		return v.Visit(expr)
+	}
+	return nil
+}
+
+func (v *DefaultASTVisitor) VisitColumnTransformerExpr(expr *ColumnTransformerExpr) error {
+	if v.Visit != nil {
+		return v.Visit(expr)
+	}
+	return nil
+}
+
+func (v *DefaultASTVisitor) VisitApplyTransformer(expr *ApplyTransformer) error {
+	if v.Visit != nil {
+		return v.Visit(expr)
+	}
+	return nil
+}
+
+func (v *DefaultASTVisitor) VisitExceptTransformer(expr *ExceptTransformer) error {
+	if v.Visit != nil {
+		return v.Visit(expr)
+	}
+	return nil
+}
+
+func (v *DefaultASTVisitor) VisitReplaceTransformer(expr *ReplaceTransformer) error {
+	if v.Visit != nil {
+		return v.Visit(expr)
+	}
+	return nil
+}
+
+func (v *DefaultASTVisitor) VisitFilterExpr(expr *FilterExpr) error {
+	if v.Visit != nil {
+		return v.Visit(expr)
+	}
+	return nil
+}
+
+func (v *DefaultASTVisitor) VisitJSONPathExpr(expr *JSONPathExpr) error {
+	if v.Visit != nil {
+		return v.Visit(expr)
+	}
+	return nil
+}
+
+func (v *DefaultASTVisitor) VisitSetOperationExpr(expr *SetOperationExpr) error {
+	if v.Visit != nil {
+		return v.Visit(expr)
+	}
+	return nil
+}
+
+func (v *DefaultASTVisitor) VisitWithFillExpr(expr *WithFillExpr) error {
+	if v.Visit != nil {
+		return v.Visit(expr)
+	}
+	return nil
+}
+
+func (v *DefaultASTVisitor) VisitInterpolateExpr(expr *InterpolateExpr) error {
+	if v.Visit != nil {
+		return v.Visit(expr)
+	}
+	return nil
+}
+
+func (v *DefaultASTVisitor) VisitInterpolateItem(expr *InterpolateItem) error {
+	if v.Visit != nil {
+		return v.Visit(expr)
+	}
+	return nil
+}
+
+func (v *DefaultASTVisitor) VisitRollupExpr(expr *RollupExpr) error {
+	if v.Visit != nil {
+		return v.Visit(expr)
+	}
+	return nil
+}
+
+func (v *DefaultASTVisitor) VisitCubeExpr(expr *CubeExpr) error {
+	if v.Visit != nil {
+		return v.Visit(expr)
+	}
+	return nil
+}
+
+func (v *DefaultASTVisitor) VisitGroupingSetsExpr(expr *GroupingSetsExpr) error {
+	if v.Visit != nil {
+		return v.Visit(expr)
+	}
+	return nil
+}
+
+func (v *DefaultASTVisitor) VisitGroupingSet(expr *GroupingSet) error {
+	if v.Visit != nil {
+		return v.Visit(expr)
+	}
+	return nil
+}
+
+func (v *DefaultASTVisitor) VisitQualifyExpr(expr *QualifyExpr) error {
+	if v.Visit != nil {
+		return v.Visit(expr)
+	}
+	return nil
+}
+
 func (v *DefaultASTVisitor) VisitAlterTable(expr *AlterTable) error {
 	if v.Visit != nil {
 		return v.Visit(expr)
@@ -387,6 +568,13 @@ func (v *DefaultASTVisitor) VisitUUID(expr *UUID) error {
 	return nil
 }
 
+func (v *DefaultASTVisitor) VisitQueryParameterExpr(expr *QueryParameterExpr) error {
+	if v.Visit != nil {
+		return v.Visit(expr)
+	}
+	return nil
+}
+
 func (v *DefaultASTVisitor) VisitCreateDatabase(expr *CreateDatabase) error {
 	if v.Visit != nil {
 		return v.Visit(expr)
@@ -632,6 +820,20 @@ func (v *DefaultASTVisitor) VisitArrayParamList(expr *ArrayParamList) error {
 	return nil
 }
 
+func (v *DefaultASTVisitor) VisitTupleLiteral(expr *TupleLiteral) error {
+	if v.Visit != nil {
+		return v.Visit(expr)
+	}
+	return nil
+}
+
+func (v *DefaultASTVisitor) VisitMapLiteral(expr *MapLiteral) error {
+	if v.Visit != nil {
+		return v.Visit(expr)
+	}
+	return nil
+}
+
 func (v *DefaultASTVisitor) VisitObjectParams(expr *ObjectParams) error {
 	if v.Visit != nil {
 		return v.Visit(expr)
@@ -639,6 +841,20 @@ func (v *DefaultASTVisitor) VisitObjectParams(expr *ObjectParams) error {
 	return nil
 }
 
+func (v *DefaultASTVisitor) VisitIndexExpr(expr *IndexExpr) error {
+	if v.Visit != nil {
+		return v.Visit(expr)
+	}
+	return nil
+}
+
+func (v *DefaultASTVisitor) VisitTupleElementExpr(expr *TupleElementExpr) error {
+	if v.Visit != nil {
+		return v.Visit(expr)
+	}
+	return nil
+}
+
 func (v *DefaultASTVisitor) VisitFunctionExpr(expr *FunctionExpr) error {
 	if v.Visit != nil {
 		return v.Visit(expr)
@@ -1223,3 +1439,92 @@ func (v *DefaultASTVisitor) VisitGrantPrivilegeExpr(expr *GrantPrivilegeExpr) er
 func (v *DefaultASTVisitor) enter(expr Expr) {}
 
 func (v *DefaultASTVisitor) leave(expr Expr) {}
+
+// QueryParameters returns the query parameters like {name:Type} which the statement expects,
+// a parameter used more than once is listed by its first occurrence.
+func QueryParameters(stmt Expr) []*QueryParameterExpr {
+	params := make([]*QueryParameterExpr, 0)
+	seen := make(map[string]bool)
+	visitor := DefaultASTVisitor{
+		Visit: func(expr Expr) error {
+			if param, ok := expr.(*QueryParameterExpr); ok && !seen[param.Name.Name] {
+				seen[param.Name.Name] = true
+				params = append(params, param)
+			}
+			return nil
+		},
+	}
+	_ = stmt.Accept(&visitor)
+	return params
+}
+
+// lambdaScopeVisitor resolves the identifiers referring to lambda parameters.
+type lambdaScopeVisitor struct {
+	DefaultASTVisitor
+	scopes []*LambdaExpr
+	// identifiers which are names rather than references, e.g. function names or aliases
+	names map[*Ident]bool
+	refs  map[*Ident]*LambdaExpr
+}
+
+func (v *lambdaScopeVisitor) enter(expr Expr) {
+	switch expr := expr.(type) {
+	case *LambdaExpr:
+		v.scopes = append(v.scopes, expr)
+	case *FunctionExpr:
+		v.names[expr.Name] = true
+	case *NestedIdentifier:
+		v.names[expr.DotIdent] = true
+	case *ColumnIdentifier:
+		// only the leading part may refer to a lambda parameter
+		if expr.Database != nil {
+			v.names[expr.Table] = true
+		}
+		v.names[expr.Column] = true
+	case *JSONPathExpr:
+		for _, name := range expr.Names[1:] {
+			v.names[name] = true
+		}
+	case *AliasExpr:
+		if alias, ok := expr.Alias.(*Ident); ok {
+			v.names[alias] = true
+		}
+	case *ScalarTypeExpr:
+		v.names[expr.Name] = true
+	case *QueryParameterExpr:
+		v.names[expr.Name] = true
+	}
+}
+
+func (v *lambdaScopeVisitor) leave(expr Expr) {
+	if _, ok := expr.(*LambdaExpr); ok {
+		v.scopes = v.scopes[:len(v.scopes)-1]
+	}
+}
+
+func (v *lambdaScopeVisitor) VisitIdent(ident *Ident) error {
+	if v.names[ident] || ident.Parameter != nil {
+		return nil
+	}
+	// the parameters of inner lambdas shadow the outer ones
+	for i := len(v.scopes) - 1; i >= 0; i-- {
+		for _, param := range v.scopes[i].Params {
+			if param.Name == ident.Name {
+				v.refs[ident] = v.scopes[i]
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+// LambdaParamRefs returns the identifiers which refer to lambda parameters instead of columns,
+// including the parameter declarations, mapped to the lambda expressions declaring them.
+func LambdaParamRefs(stmt Expr) map[*Ident]*LambdaExpr {
+	visitor := &lambdaScopeVisitor{
+		names: make(map[*Ident]bool),
+		refs:  make(map[*Ident]*LambdaExpr),
+	}
+	_ = stmt.Accept(visitor)
+	return visitor.refs
+}
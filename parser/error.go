@@ -0,0 +1,126 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ParseError describes where and why the input failed to parse.
+type ParseError struct {
+	Line   int // 1-based line number
+	Column int // 1-based column number counted in characters
+	Offset int // byte offset of the offending token in the input
+	// Token is the offending token, its kind is TokenEOF if the input ended unexpectedly.
+	Token Token
+	// Expected lists the tokens or keywords which were expected instead, if they are known.
+	Expected []string
+	// StatementIndex is the 0-based index of the statement which failed to parse.
+	StatementIndex int
+	// StatementStart and StatementEnd are the byte range of the statement which failed to parse,
+	// the end is the offset of the terminating ';' or the length of the input.
+	StatementStart int
+	StatementEnd   int
+	Err            error
+
+	input string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d:%d %s", e.Line, e.Column, e.Err.Error())
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Pretty returns the error message along with the offending line and a caret under the offending token.
+func (e *ParseError) Pretty() string {
+	lineStart := strings.LastIndexByte(e.input[:e.Offset], '\n') + 1
+	lineEnd := strings.IndexByte(e.input[e.Offset:], '\n')
+	if lineEnd < 0 {
+		lineEnd = len(e.input)
+	} else {
+		lineEnd += e.Offset
+	}
+	var buf strings.Builder
+	buf.WriteString(e.Error())
+	buf.WriteByte('\n')
+	buf.WriteString(e.input[lineStart:lineEnd])
+	buf.WriteByte('\n')
+	_, end := tokenRange(e.Token)
+	width := utf8.RuneCountInString(e.input[e.Offset:end])
+	if width == 0 {
+		width = 1
+	}
+	buf.WriteString(strings.Repeat(" ", e.Column-1))
+	buf.WriteString(strings.Repeat("^", width))
+	buf.WriteByte('\n')
+	return buf.String()
+}
+
+// tokenRange returns the byte range of the token in the input, the range of quoted tokens seen
+// by the parser only covers the content between the quotes.
+func tokenRange(token Token) (int, int) {
+	switch {
+	case token.QuoteType == Heredoc:
+		return int(token.Pos) - len(token.HeredocTag) - 2, int(token.End) + len(token.HeredocTag) + 2
+	case token.Kind == TokenString, token.QuoteType == BackTicks, token.QuoteType == DoubleQuote:
+		return int(token.Pos) - 1, int(token.End) + 1
+	default:
+		return int(token.Pos), int(token.End)
+	}
+}
+
+// expectedError is returned by the parser when the last token isn't one of the expected tokens or keywords.
+type expectedError struct {
+	expected []string
+	message  string
+}
+
+func (e *expectedError) Error() string {
+	return e.message
+}
+
+// expectedError returns an error telling the last token isn't any of the expected tokens or keywords.
+func (p *Parser) expectedError(expected ...string) error {
+	got := string(TokenEOF)
+	if p.last() != nil {
+		got = strconv.Quote(p.last().String)
+	}
+	return &expectedError{
+		expected: expected,
+		message:  fmt.Sprintf("expected %s, but got %s", strings.Join(expected, " or "), got),
+	}
+}
+
+func (p *Parser) newParseError(err error, statementIndex int) *ParseError {
+	token := Token{Kind: TokenEOF, Pos: Pos(p.lexer.current), End: Pos(p.lexer.current)}
+	if p.last() != nil {
+		token = *p.last()
+	} else if !p.lexer.isEOF() {
+		// the lexer failed to scan a token at the current position
+		_, size := utf8.DecodeRuneInString(p.lexer.input[p.lexer.current:])
+		token.String = p.lexer.slice(0, size)
+		token.Kind = TokenKind(token.String)
+		token.End = Pos(p.lexer.current + size)
+	}
+	offset, _ := tokenRange(token)
+	lineStart := strings.LastIndexByte(p.lexer.input[:offset], '\n') + 1
+	parseError := &ParseError{
+		Line:           strings.Count(p.lexer.input[:offset], "\n") + 1,
+		Column:         utf8.RuneCountInString(p.lexer.input[lineStart:offset]) + 1,
+		Offset:         offset,
+		Token:          token,
+		StatementIndex: statementIndex,
+		Err:            err,
+		input:          p.lexer.input,
+	}
+	var expected *expectedError
+	if errors.As(err, &expected) {
+		parseError.Expected = expected.expected
+	}
+	return parseError
+}
@@ -3,6 +3,8 @@ package parser
 import (
 	"fmt"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
 func TabSpaces(level int) string {
@@ -16,6 +18,13 @@ func NewLine(level int) string {
 	return fmt.Sprintf("\n%s", TabSpaces(level))
 }
 
+// writeNewLine starts a new line unless the builder has just started it, like after a trailing line comment.
+func writeNewLine(builder *strings.Builder, level int) {
+	if newLine := NewLine(level); !strings.HasSuffix(builder.String(), newLine) {
+		builder.WriteString(newLine)
+	}
+}
+
 func IsDigit(c byte) bool {
 	return '0' <= c && c <= '9'
 }
@@ -24,6 +33,17 @@ func IsHexDigit(c byte) bool {
 	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
 }
 
+func isDigitOfBase(c byte, base int) bool {
+	switch base {
+	case 2:
+		return c == '0' || c == '1'
+	case 16:
+		return IsHexDigit(c)
+	default:
+		return IsDigit(c)
+	}
+}
+
 func IsIdentStart(c byte) bool {
 	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || c == '_'
 }
@@ -31,3 +51,186 @@ func IsIdentStart(c byte) bool {
 func IsIdentPart(c byte) bool {
 	return '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || c == '_'
 }
+
+// IsIdentStartRune is the UTF-8 aware version of IsIdentStart, non-ASCII letters are allowed.
+func IsIdentStartRune(r rune) bool {
+	if r < utf8.RuneSelf {
+		return IsIdentStart(byte(r))
+	}
+	return unicode.IsLetter(r)
+}
+
+// IsIdentPartRune is the UTF-8 aware version of IsIdentPart, non-ASCII letters, digits and marks are allowed.
+func IsIdentPartRune(r rune) bool {
+	if r < utf8.RuneSelf {
+		return IsIdentPart(byte(r))
+	}
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r)
+}
+
+// isBareIdent reports whether the name can be written as an identifier without quotes.
+func isBareIdent(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		if i == 0 && !IsIdentStartRune(r) && r != '$' || i > 0 && !IsIdentPartRune(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// unescapeIdent decodes the quoted identifier, where the quote and the backslash are escaped by
+// a backslash, and the quote may be doubled as well. Other escapes keep the backslash.
+func unescapeIdent(raw string, quote byte) string {
+	if !strings.ContainsAny(raw, "\\"+string(quote)) {
+		return raw
+	}
+	var builder strings.Builder
+	for i := 0; i < len(raw); i++ {
+		c := raw[i]
+		switch {
+		case c == '\\' && i+1 < len(raw) && (raw[i+1] == '\\' || raw[i+1] == quote),
+			c == quote && i+1 < len(raw) && raw[i+1] == quote:
+			builder.WriteByte(raw[i+1])
+			i++
+		default:
+			builder.WriteByte(c)
+		}
+	}
+	return builder.String()
+}
+
+// escapeIdent is the reverse of unescapeIdent, the result can be safely enclosed in the quotes.
+func escapeIdent(name string, quote byte) string {
+	if !strings.ContainsAny(name, "\\"+string(quote)) {
+		return name
+	}
+	var builder strings.Builder
+	for i := 0; i < len(name); i++ {
+		if name[i] == '\\' || name[i] == quote {
+			builder.WriteByte('\\')
+		}
+		builder.WriteByte(name[i])
+	}
+	return builder.String()
+}
+
+// unescapeString decodes the escape sequences of a single-quoted string literal.
+// It follows ClickHouse: unknown escapes like `\d` keep the backslash so that
+// LIKE patterns and regular expressions work as written.
+func unescapeString(raw string) string {
+	if !strings.ContainsAny(raw, `\'`) {
+		return raw
+	}
+	var builder strings.Builder
+	for i := 0; i < len(raw); i++ {
+		c := raw[i]
+		switch {
+		case c == '\'' && i+1 < len(raw) && raw[i+1] == '\'':
+			builder.WriteByte('\'')
+			i++
+		case c == '\\' && i+1 < len(raw):
+			i++
+			next := raw[i]
+			if next == 'x' && i+2 < len(raw) && IsHexDigit(raw[i+1]) && IsHexDigit(raw[i+2]) {
+				builder.WriteByte(hexValue(raw[i+1])<<4 | hexValue(raw[i+2]))
+				i += 2
+				continue
+			}
+			decoded := next
+			switch next {
+			case 'a':
+				decoded = '\a'
+			case 'b':
+				decoded = '\b'
+			case 'e':
+				decoded = '\x1b'
+			case 'f':
+				decoded = '\f'
+			case 'n':
+				decoded = '\n'
+			case 'r':
+				decoded = '\r'
+			case 't':
+				decoded = '\t'
+			case 'v':
+				decoded = '\v'
+			case '0':
+				decoded = 0
+			default:
+				if !isPlainEscape(next) {
+					builder.WriteByte('\\')
+				}
+			}
+			builder.WriteByte(decoded)
+		default:
+			builder.WriteByte(c)
+		}
+	}
+	return builder.String()
+}
+
+// isPlainEscape reports whether `\c` decodes to c alone, other unknown escapes keep the backslash.
+func isPlainEscape(c byte) bool {
+	switch c {
+	case '\\', '\'', '"', '`', '/', '=':
+		return true
+	}
+	return c < 0x20 || c == 0x7f
+}
+
+// escapeString is the reverse of unescapeString, the result can be safely
+// enclosed in single quotes.
+func escapeString(s string) string {
+	var builder strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch c {
+		case '\\':
+			// a backslash that unescapeString keeps as is can be written back verbatim
+			if i+1 < len(s) && !isPlainEscape(s[i+1]) && !strings.ContainsRune("abefnrtv0x", rune(s[i+1])) {
+				builder.WriteByte('\\')
+			} else {
+				builder.WriteString(`\\`)
+			}
+		case '\'':
+			builder.WriteString(`\'`)
+		case '\a':
+			builder.WriteString(`\a`)
+		case '\b':
+			builder.WriteString(`\b`)
+		case '\f':
+			builder.WriteString(`\f`)
+		case '\n':
+			builder.WriteString(`\n`)
+		case '\r':
+			builder.WriteString(`\r`)
+		case '\t':
+			builder.WriteString(`\t`)
+		case '\v':
+			builder.WriteString(`\v`)
+		case 0:
+			builder.WriteString(`\0`)
+		default:
+			if c < 0x20 || c == 0x7f {
+				builder.WriteString(fmt.Sprintf(`\x%02X`, c))
+			} else {
+				builder.WriteByte(c)
+			}
+		}
+	}
+	return builder.String()
+}
+
+func hexValue(c byte) byte {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
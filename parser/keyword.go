@@ -99,6 +99,7 @@ const (
 	KeywordInjective    = "INJECTIVE"
 	KeywordInner        = "INNER"
 	KeywordInsert       = "INSERT"
+	KeywordIntersect    = "INTERSECT"
 	KeywordInterval     = "INTERVAL"
 	KeywordInto         = "INTO"
 	KeywordIs           = "IS"
@@ -151,6 +152,7 @@ const (
 	KeywordPrewhere     = "PREWHERE"
 	KeywordPrimary      = "PRIMARY"
 	KeywordProjection   = "PROJECTION"
+	KeywordQualify      = "QUALIFY"
 	KeywordQuarter      = "QUARTER"
 	KeywordQuery        = "QUERY"
 	KeywordQueues       = "QUEUES"
@@ -322,6 +324,7 @@ var keywords = NewSet(
 	KeywordInjective,
 	KeywordInner,
 	KeywordInsert,
+	KeywordIntersect,
 	KeywordInterval,
 	KeywordInto,
 	KeywordIs,
@@ -374,6 +377,7 @@ var keywords = NewSet(
 	KeywordPrewhere,
 	KeywordPrimary,
 	KeywordProjection,
+	KeywordQualify,
 	KeywordQuarter,
 	KeywordQuery,
 	KeywordQueues,
@@ -15,12 +15,18 @@ const (
 	TokenInt     TokenKind = "<int>"
 	TokenFloat   TokenKind = "<float>"
 	TokenString  TokenKind = "<string>"
+
+	// TokenWhitespace and TokenComment are only returned by Next if whitespace and comments are emitted.
+	TokenWhitespace TokenKind = "<whitespace>"
+	TokenComment    TokenKind = "<comment>"
 )
 
 const (
 	Unquoted = iota + 1
 	DoubleQuote
 	BackTicks
+	SingleQuote
+	Heredoc
 )
 
 type Pos int
@@ -30,16 +36,21 @@ type Token struct {
 	Pos Pos
 	End Pos
 
-	Kind      TokenKind
-	String    string
-	Base      int // 10 or 16 on TokenInt
-	QuoteType int
+	Kind       TokenKind
+	String     string
+	Base       int // 2, 10 or 16 on TokenInt and TokenFloat
+	QuoteType  int
+	HeredocTag string // the tag of $tag$...$tag$ on TokenString
 }
 
 type Lexer struct {
 	input     string
 	current   int
 	lastToken *Token
+
+	retainComments bool
+	comments       []*Comment
+	emitTrivia     bool
 }
 
 func NewLexer(buf string) *Lexer {
@@ -68,35 +79,45 @@ func (l *Lexer) isKeyword(ident string) bool {
 
 func (l *Lexer) consumeNumber() error {
 	i := 0
-	base := 10
 	if l.peekN(0) == '+' || l.peekN(0) == '-' {
 		// skip sign
 		i++
 	}
-	if l.peekN(0) == '0' && l.peekOk(1) && l.peekN(1) == 'x' {
-		i += 2
-		base = 16
+	base := 10
+	if l.peekOk(i+1) && l.peekN(i) == '0' {
+		switch l.peekN(i + 1) {
+		case 'x', 'X':
+			base = 16
+			i += 2
+		case 'b', 'B':
+			base = 2
+			i += 2
+		}
 	}
 
+	hasDot := false
 	hasExp := false
 	tokenKind := TokenInt
 	hasNumberPart := false
 	for l.peekOk(i) {
-		hasNumberPart = true
 		c := l.peekN(i)
 		switch {
-		case base == 10 && IsDigit(c):
+		case isDigitOfBase(c, base):
+			hasNumberPart = true
 			i++
 			continue
-		case base == 16 && IsHexDigit(c):
+		case c == '_' && hasNumberPart && isDigitOfBase(l.peekN(i-1), base) &&
+			l.peekOk(i+1) && isDigitOfBase(l.peekN(i+1), base):
+			// digit separator like 1_000_000
 			i++
 			continue
-		case c == '.': // float
+		case c == '.' && base != 2 && !hasDot: // float
 			tokenKind = TokenFloat
+			hasDot = true
 			i++
 			continue
-		case base != 16 && (c == 'e' || c == 'E' || c == 'p' || c == 'P'):
-			if hasExp {
+		case base == 10 && (c == 'e' || c == 'E') || base == 16 && (c == 'p' || c == 'P'):
+			if !hasNumberPart || hasExp {
 				return errors.New("invalid number")
 			}
 			i++
@@ -106,12 +127,15 @@ func (l *Lexer) consumeNumber() error {
 			if !l.peekOk(i) || !IsDigit(l.peekN(i)) {
 				return errors.New("exponent part should contain at least one digit")
 			}
+			for l.peekOk(i) && IsDigit(l.peekN(i)) {
+				i++
+			}
 			hasExp = true
 			continue
 		}
 		break
 	}
-	if (l.peekOk(i) && IsIdentPart(l.peekN(i))) || !hasNumberPart {
+	if l.identRuneSize(i, false) > 0 || !hasNumberPart {
 		return errors.New("invalid number")
 	}
 	l.lastToken = &Token{
@@ -125,6 +149,40 @@ func (l *Lexer) consumeNumber() error {
 	return nil
 }
 
+// isOperandEnd reports whether the token ends an operand, so that the following sign is the binary
+// operator like a -1, and the following dot is the tuple access operator like t.1 or arr[1].2
+func isOperandEnd(token *Token) bool {
+	if token == nil {
+		return false
+	}
+	switch token.Kind {
+	case TokenIdent, TokenInt, TokenFloat, TokenString, ")", "]", "}":
+		return true
+	}
+	return false
+}
+
+// consumeTupleIndex consumes the decimal digits after the dot of tuple access like t.1.2,
+// which would be lexed as the float number .2 otherwise.
+func (l *Lexer) consumeTupleIndex() error {
+	i := 0
+	for l.peekOk(i) && IsDigit(l.peekN(i)) {
+		i++
+	}
+	if l.identRuneSize(i, false) > 0 {
+		return errors.New("invalid tuple index")
+	}
+	l.lastToken = &Token{
+		Kind:   TokenInt,
+		String: l.slice(0, i),
+		Pos:    Pos(l.current),
+		End:    Pos(l.current + i),
+		Base:   10,
+	}
+	l.skipN(i)
+	return nil
+}
+
 func (l *Lexer) consumeIdent(_ Pos) error {
 	token := &Token{}
 	quoteType := Unquoted
@@ -142,16 +200,27 @@ func (l *Lexer) consumeIdent(_ Pos) error {
 		if l.peekN(i) == '$' {
 			i++
 		}
-		for l.peekOk(i) && IsIdentPart(l.peekN(i)) {
-			i++
+		for size := l.identRuneSize(i, false); size > 0; size = l.identRuneSize(i, false) {
+			i += size
 		}
 	} else {
-		for l.peekOk(i) && (quoteType == BackTicks && l.peekN(i) != '`' ||
-			quoteType == DoubleQuote && l.peekN(i) != '"') {
+		quote := byte('"')
+		if quoteType == BackTicks {
+			quote = '`'
+		}
+		for l.peekOk(i) {
+			c := l.peekN(i)
+			if c == '\\' && l.peekOk(i+1) || c == quote && l.peekOk(i+1) && l.peekN(i+1) == quote {
+				// escaped character like \` or ``
+				i += 2
+				continue
+			}
+			if c == quote {
+				break
+			}
 			i++
 		}
-		if !l.peekOk(i) || (quoteType == BackTicks && l.peekN(i) != '`') ||
-			(quoteType == DoubleQuote && l.peekN(i) != '"') {
+		if !l.peekOk(i) {
 			return fmt.Errorf("unclosed quoted identifier: %s", l.slice(0, i))
 		}
 	}
@@ -164,6 +233,11 @@ func (l *Lexer) consumeIdent(_ Pos) error {
 	token.Pos = Pos(l.current)
 	token.End = Pos(l.current + i)
 	token.String = slice
+	if quoteType == BackTicks {
+		token.String = unescapeIdent(slice, '`')
+	} else if quoteType == DoubleQuote {
+		token.String = unescapeIdent(slice, '"')
+	}
 	token.QuoteType = quoteType
 	l.lastToken = token
 
@@ -175,64 +249,126 @@ func (l *Lexer) consumeIdent(_ Pos) error {
 }
 
 func (l *Lexer) consumeSingleLineComment() {
-	l.skipN(2)
-	i := 0
+	i := 2
 	for l.peekOk(i) && l.peekN(i) != '\r' && l.peekN(i) != '\n' {
 		i++
 	}
-	l.skipN(i + 1)
+	l.recordComment(i)
+	l.skipN(i)
 }
 
 func (l *Lexer) consumeMultiLineComment() {
-	l.skipN(2)
-	i := 0
-	for !l.isEOF() {
+	i := 2
+	for l.peekOk(i) {
 		if l.peekOk(i+1) && l.peekN(i) == '*' && l.peekN(i+1) == '/' {
 			i += 2
 			break
 		}
 		i++
 	}
+	l.recordComment(i)
 	l.skipN(i)
 }
 
+// recordComment keeps the comment of length n at the current position if comments are retained.
+func (l *Lexer) recordComment(n int) {
+	if !l.retainComments {
+		return
+	}
+	// comments are scanned again when peeking tokens
+	if len(l.comments) > 0 && l.comments[len(l.comments)-1].CommentPos >= Pos(l.current) {
+		return
+	}
+	l.comments = append(l.comments, &Comment{
+		CommentPos: Pos(l.current),
+		CommentEnd: Pos(l.current + n),
+		Text:       l.slice(0, n),
+	})
+}
+
 func (l *Lexer) consumeString() error {
 	i := 1
-	endChar := byte('\'')
-	for l.peekOk(i) && l.peekN(i) != endChar {
+	for l.peekOk(i) {
+		c := l.peekN(i)
+		if c == '\\' {
+			// skip the escaped character, \xHH needs exactly two hex digits
+			if l.peekOk(i+1) && l.peekN(i+1) == 'x' {
+				if !l.peekOk(i+3) || !IsHexDigit(l.peekN(i+2)) || !IsHexDigit(l.peekN(i+3)) {
+					return fmt.Errorf("invalid hex escape sequence in string: %s", l.slice(0, i+2))
+				}
+				i += 4
+				continue
+			}
+			i += 2
+			continue
+		}
+		if c == '\'' {
+			// two consecutive quotes stand for a single quote
+			if l.peekOk(i+1) && l.peekN(i+1) == '\'' {
+				i += 2
+				continue
+			}
+			break
+		}
 		i++
 	}
 	if !l.peekOk(i) {
 		return errors.New("invalid string")
 	}
 	l.lastToken = &Token{
-		Kind:   TokenString,
-		String: l.slice(1, i),
-		Pos:    Pos(l.current + 1),
-		End:    Pos(l.current + i),
+		Kind:      TokenString,
+		String:    l.slice(1, i),
+		Pos:       Pos(l.current + 1),
+		End:       Pos(l.current + i),
+		QuoteType: SingleQuote,
 	}
 	l.skipN(i + 1)
 	return nil
 }
 
+// peekHeredocTag checks if the input starts with a heredoc opening like $$ or $tag$.
+func (l *Lexer) peekHeredocTag() (string, bool) {
+	i := 1
+	for l.peekOk(i) && IsIdentPart(l.peekN(i)) {
+		i++
+	}
+	if l.peekOk(i) && l.peekN(i) == '$' {
+		return l.slice(1, i), true
+	}
+	return "", false
+}
+
+// consumeHeredoc consumes the string between $tag$ and $tag$, escape sequences are not applied.
+func (l *Lexer) consumeHeredoc(tag string) error {
+	delimiter := "$" + tag + "$"
+	start := len(delimiter)
+	n := strings.Index(l.input[l.current+start:], delimiter)
+	if n < 0 {
+		return fmt.Errorf("unclosed heredoc string: %s", delimiter)
+	}
+	l.lastToken = &Token{
+		Kind:       TokenString,
+		String:     l.slice(start, start+n),
+		Pos:        Pos(l.current + start),
+		End:        Pos(l.current + start + n),
+		QuoteType:  Heredoc,
+		HeredocTag: tag,
+	}
+	l.skipN(start + n + len(delimiter))
+	return nil
+}
+
 func (l *Lexer) skipComments() {
-	for !l.isEOF() {
-		switch l.peekN(0) {
-		case '-':
-			if l.peekOk(1) && l.peekN(1) == '-' {
-				l.consumeSingleLineComment()
-				continue
-			}
-			return
-		case '/': // multi-line comment
-			if l.peekOk(1) && l.peekN(1) == '*' {
-				l.consumeMultiLineComment()
-				continue
-			}
+	for {
+		l.skipSpace()
+		if !l.peekOk(1) {
 			return
-		case '\r', '\n':
-			// skip \r\n or \n\r
-			l.skipN(1)
+		}
+		switch {
+		case l.peekN(0) == '-' && l.peekN(1) == '-':
+			l.consumeSingleLineComment()
+		case l.peekN(0) == '/' && l.peekN(1) == '*': // multi-line comment
+			l.consumeMultiLineComment()
 		default:
 			return
 		}
@@ -253,11 +389,10 @@ func (l *Lexer) peekToken() (*Token, error) {
 }
 
 func (l *Lexer) consumeToken() error {
-	l.skipSpace()
 	// clear last token
+	prevToken := l.lastToken
 	l.lastToken = nil
 	l.skipComments()
-	l.skipSpace()
 	if l.isEOF() {
 		return nil
 	}
@@ -278,7 +413,7 @@ func (l *Lexer) consumeToken() error {
 		}
 
 	case '+', '-':
-		if l.peekOk(1) && IsDigit(l.peekN(1)) {
+		if l.peekOk(1) && IsDigit(l.peekN(1)) && !isOperandEnd(prevToken) {
 			return l.consumeNumber()
 		} else if l.peekOk(1) && l.peekN(1) == '>' {
 			l.lastToken = &Token{
@@ -291,8 +426,16 @@ func (l *Lexer) consumeToken() error {
 			return nil
 		}
 	case '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		if prevToken != nil && prevToken.Kind == "." {
+			return l.consumeTupleIndex()
+		}
 		return l.consumeNumber()
-	case '`', '$', '"':
+	case '$':
+		if tag, ok := l.peekHeredocTag(); ok {
+			return l.consumeHeredoc(tag)
+		}
+		return l.consumeIdent(Pos(l.current))
+	case '`', '"':
 		return l.consumeIdent(Pos(l.current))
 	case '\'':
 		return l.consumeString()
@@ -308,22 +451,14 @@ func (l *Lexer) consumeToken() error {
 			return nil
 		}
 	case '.':
-		// check if the next token is a number. If so, parse it as a float number
-		if l.peekOk(1) && IsDigit(l.peekN(1)) {
+		// the dot after an identifier, a closing bracket or a tuple index is the tuple access
+		// like t.1 or arr[1].2, otherwise it starts a float number like .5
+		if l.peekOk(1) && IsDigit(l.peekN(1)) && !isOperandEnd(prevToken) {
 			return l.consumeNumber()
 		}
-		// check if the previous lastToken is an Ident. If so, it's a field name.
-		if l.lastToken != nil && l.lastToken.Kind != TokenIdent {
-			return fmt.Errorf("'.' should be after an Ident, but got <%q>", l.lastToken.Kind)
-		}
-	}
-
-	// The subsequent lastToken after the dot should be an Ident.
-	if l.lastToken != nil && l.lastToken.Kind == "." && !IsIdentStart(l.peekN(0)) {
-		return fmt.Errorf("'.' should follow with an Ident, but got <%q>", l.lastToken.Kind)
 	}
 
-	if IsIdentStart(l.peekN(0)) {
+	if l.identRuneSize(0, true) > 0 {
 		return l.consumeIdent(Pos(l.current))
 	}
 
@@ -337,6 +472,85 @@ func (l *Lexer) consumeToken() error {
 	return nil
 }
 
+// EmitWhitespaceAndComments makes Next return whitespace and comments as TokenWhitespace and
+// TokenComment tokens, so that the tokens cover every byte of the input.
+func (l *Lexer) EmitWhitespaceAndComments() *Lexer {
+	l.emitTrivia = true
+	return l
+}
+
+// Next consumes and returns the next token, the token kind is TokenEOF at the end of input.
+// Different from the tokens seen by the parser, Pos and End of the returned token are the exact
+// byte range of the token in the input including quotes, so input[Pos:End] is its source text.
+func (l *Lexer) Next() (Token, error) {
+	if !l.emitTrivia {
+		l.skipComments()
+	}
+	start := l.current
+	var token Token
+	switch {
+	case l.isEOF():
+		return Token{Kind: TokenEOF, Pos: Pos(start), End: Pos(start)}, nil
+	case l.emitTrivia && unicode.IsSpace(l.peekRune()):
+		l.skipSpace()
+		token = Token{Kind: TokenWhitespace}
+	case l.emitTrivia && l.peekOk(1) && l.peekN(0) == '-' && l.peekN(1) == '-':
+		l.consumeSingleLineComment()
+		token = Token{Kind: TokenComment}
+	case l.emitTrivia && l.peekOk(1) && l.peekN(0) == '/' && l.peekN(1) == '*':
+		l.consumeMultiLineComment()
+		token = Token{Kind: TokenComment}
+	default:
+		if err := l.consumeToken(); err != nil {
+			return Token{}, err
+		}
+		token = *l.lastToken
+	}
+	token.Pos = Pos(start)
+	token.End = Pos(l.current)
+	if token.Kind == TokenWhitespace || token.Kind == TokenComment {
+		token.String = l.input[start:l.current]
+	}
+	return token, nil
+}
+
+// Tokenize splits the input into tokens without parsing it. If withWhitespaceAndComments is true,
+// whitespace and comments are included and the source texts of the tokens join to the input.
+func Tokenize(input string, withWhitespaceAndComments bool) ([]Token, error) {
+	lexer := NewLexer(input)
+	if withWhitespaceAndComments {
+		lexer.EmitWhitespaceAndComments()
+	}
+	var tokens []Token
+	for {
+		token, err := lexer.Next()
+		if err != nil {
+			return nil, err
+		}
+		if token.Kind == TokenEOF {
+			return tokens, nil
+		}
+		tokens = append(tokens, token)
+	}
+}
+
+// identRuneSize returns the byte size of the identifier character at offset n, or 0 if there is none.
+func (l *Lexer) identRuneSize(n int, start bool) int {
+	if !l.peekOk(n) {
+		return 0
+	}
+	r, size := utf8.DecodeRuneInString(l.input[l.current+n:])
+	if start && IsIdentStartRune(r) || !start && IsIdentPartRune(r) {
+		return size
+	}
+	return 0
+}
+
+func (l *Lexer) peekRune() rune {
+	r, _ := utf8.DecodeRuneInString(l.input[l.current:])
+	return r
+}
+
 func (l *Lexer) isEOF() bool {
 	return l.current >= len(l.input)
 }
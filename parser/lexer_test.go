@@ -44,6 +44,72 @@ func TestConsumeString(t *testing.T) {
 		require.Equal(t, strings.Trim(s, "'"), lexer.lastToken.String)
 		require.True(t, lexer.isEOF())
 	}
+
+	t.Run("Escaped string", func(t *testing.T) {
+		strs := map[string]string{
+			`'it\'s'`:       "it's",
+			`'a''b'`:        "a'b",
+			`'\n\t\r\0'`:    "\n\t\r\x00",
+			`'\x41\x4a'`:    "AJ",
+			`'C:\\temp'`:    `C:\temp`,
+			`'^\d+$'`:       `^\d+$`,
+			`'100\%'`:       `100\%`,
+			`'\"quoted\"'`:  `"quoted"`,
+			`''''`:          "'",
+			`'\'\''`:        "''",
+			`'\\'`:          `\`,
+			`'emoji 😀\x7f'`: "emoji 😀\x7f",
+		}
+		for s, value := range strs {
+			lexer := NewLexer(s)
+			err := lexer.consumeToken()
+			require.NoError(t, err)
+			require.Equal(t, TokenString, lexer.lastToken.Kind)
+			require.Equal(t, s[1:len(s)-1], lexer.lastToken.String)
+			require.True(t, lexer.isEOF())
+			require.Equal(t, value, unescapeString(lexer.lastToken.String))
+			require.Equal(t, value, unescapeString(escapeString(value)))
+		}
+	})
+
+	t.Run("Heredoc string", func(t *testing.T) {
+		strs := map[string][2]string{
+			"$$hello world$$":             {"", "hello world"},
+			"$$$$":                        {"", ""},
+			"$json${\"a\": 'b\\n'}$json$": {"json", "{\"a\": 'b\\n'}"},
+			"$a$ $$ $b$ $a$":              {"a", " $$ $b$ "},
+		}
+		for s, expected := range strs {
+			lexer := NewLexer(s)
+			err := lexer.consumeToken()
+			require.NoError(t, err)
+			require.Equal(t, TokenString, lexer.lastToken.Kind)
+			require.Equal(t, Heredoc, lexer.lastToken.QuoteType)
+			require.Equal(t, expected[0], lexer.lastToken.HeredocTag)
+			require.Equal(t, expected[1], lexer.lastToken.String)
+			require.True(t, lexer.isEOF())
+		}
+
+		lexer := NewLexer("$abc")
+		require.NoError(t, lexer.consumeToken())
+		require.Equal(t, TokenIdent, lexer.lastToken.Kind)
+	})
+
+	t.Run("Invalid string", func(t *testing.T) {
+		strs := []string{
+			`'unclosed`,
+			`'escaped quote\'`,
+			`'\x4'`,
+			`'\xZZ'`,
+			"$$unclosed",
+			"$tag$unclosed$$",
+		}
+		for _, s := range strs {
+			lexer := NewLexer(s)
+			err := lexer.consumeToken()
+			require.Error(t, err)
+		}
+	})
 }
 
 func TestConsumeNumber(t *testing.T) {
@@ -68,10 +134,64 @@ func TestConsumeNumber(t *testing.T) {
 		}
 	})
 
+	t.Run("Integer number with digit separators", func(t *testing.T) {
+		integers := []string{
+			"1_000_000",
+			"-1_000",
+			"1_2_3e1_0",
+		}
+		for _, i := range integers {
+			lexer := NewLexer(i)
+			err := lexer.consumeToken()
+			require.NoError(t, err)
+			require.Equal(t, TokenInt, lexer.lastToken.Kind)
+			require.Equal(t, i, lexer.lastToken.String)
+			require.True(t, lexer.isEOF())
+		}
+	})
+
+	t.Run("Binary number", func(t *testing.T) {
+		numbers := []string{
+			"0b1010",
+			"0B1",
+			"-0b1_0",
+		}
+		for _, n := range numbers {
+			lexer := NewLexer(n)
+			err := lexer.consumeToken()
+			require.NoError(t, err)
+			require.Equal(t, TokenInt, lexer.lastToken.Kind)
+			require.Equal(t, 2, lexer.lastToken.Base)
+			require.Equal(t, n, lexer.lastToken.String)
+			require.True(t, lexer.isEOF())
+		}
+	})
+
+	t.Run("Hexadecimal float number", func(t *testing.T) {
+		numbers := []string{
+			"0x1.8p3",
+			"0X1.8P-3",
+			"0xA.B",
+		}
+		for _, n := range numbers {
+			lexer := NewLexer(n)
+			err := lexer.consumeToken()
+			require.NoError(t, err)
+			require.Equal(t, TokenFloat, lexer.lastToken.Kind)
+			require.Equal(t, 16, lexer.lastToken.Base)
+			require.Equal(t, n, lexer.lastToken.String)
+			require.True(t, lexer.isEOF())
+		}
+	})
+
 	t.Run("Hexadecimal number", func(t *testing.T) {
 		numbers := []string{
 			"0x123",
 			"0x1",
+			"0X1F",
+			"0x1p4",
+			"-0xFF",
+			"0xdead_beef",
 		}
 		for _, n := range numbers {
 			lexer := NewLexer(n)
@@ -95,6 +215,15 @@ func TestConsumeNumber(t *testing.T) {
 			"123E-",
 			"0x",
 			"0xg",
+			"0X",
+			"0b",
+			"0b2",
+			"1p5",
+			"1_",
+			"1__0",
+			"0x_1",
+			"0x1p",
+			"0x1.8e3p",
 		}
 		for _, n := range invalidNumbers {
 			lexer := NewLexer(n)
@@ -175,6 +304,51 @@ func TestConsumeNumber(t *testing.T) {
 		}
 	})
 
+	t.Run("Unicode ident", func(t *testing.T) {
+		idents := []string{
+			"名字",
+			"Größe",
+			"café_1",
+			"_имя",
+			"$变量",
+			"`用户 表`",
+			"\"Straße\"",
+		}
+		for _, i := range idents {
+			lexer := NewLexer(i + " ,")
+			err := lexer.consumeToken()
+			require.NoError(t, err)
+			require.Equal(t, TokenIdent, lexer.lastToken.Kind)
+			require.Equal(t, strings.Trim(i, "`\""), lexer.lastToken.String)
+			require.Equal(t, i, (i + " ,")[lexer.current-len(i):lexer.current])
+			require.NoError(t, lexer.consumeToken())
+			require.Equal(t, TokenKind(","), lexer.lastToken.Kind)
+			require.Equal(t, Pos(len(i)+1), lexer.lastToken.Pos)
+		}
+
+		lexer := NewLexer("123名字")
+		require.Error(t, lexer.consumeToken())
+	})
+
+	t.Run("Tuple index", func(t *testing.T) {
+		inputs := map[string][]TokenKind{
+			"t.1.2":     {TokenIdent, ".", TokenInt, ".", TokenInt},
+			"f(x).1":    {TokenIdent, "(", TokenIdent, ")", ".", TokenInt},
+			"arr[1].2":  {TokenIdent, "[", TokenInt, "]", ".", TokenInt},
+			"x = .5":    {TokenIdent, "=", TokenFloat},
+			"SELECT .5": {TokenKeyword, TokenFloat},
+		}
+		for input, expected := range inputs {
+			tokens, err := Tokenize(input, false)
+			require.NoError(t, err)
+			var kinds []TokenKind
+			for _, token := range tokens {
+				kinds = append(kinds, token.Kind)
+			}
+			require.Equal(t, expected, kinds, input)
+		}
+	})
+
 	t.Run("Keyword", func(t *testing.T) {
 		for _, k := range keywords.Members() {
 			lexer := NewLexer(k)
@@ -186,3 +360,46 @@ func TestConsumeNumber(t *testing.T) {
 		}
 	})
 }
+
+func TestTokenize(t *testing.T) {
+	inputs := []string{
+		"SELECT a, b FROM t WHERE x = 1",
+		"-- leading comment\nSELECT 'it''s', $$raw$$, `quoted ident` /* block */ FROM db.t;\n",
+		"SELECT -1, 1.5e10, 0x1F, a::String, b -> b + 1\r\n\t-- trailing comment",
+		"/* only a comment */",
+		" SELECT　{id:UInt64}",
+		"",
+	}
+	for _, input := range inputs {
+		tokens, err := Tokenize(input, true)
+		require.NoError(t, err)
+		var builder strings.Builder
+		for _, token := range tokens {
+			require.Less(t, int(token.Pos), int(token.End))
+			builder.WriteString(input[token.Pos:token.End])
+		}
+		require.Equal(t, input, builder.String())
+	}
+
+	tokens, err := Tokenize("SELECT 'a' -- comment\n, `b`", false)
+	require.NoError(t, err)
+	var kinds []TokenKind
+	var sources []string
+	for _, token := range tokens {
+		kinds = append(kinds, token.Kind)
+		sources = append(sources, "SELECT 'a' -- comment\n, `b`"[token.Pos:token.End])
+	}
+	require.Equal(t, []TokenKind{TokenKeyword, TokenString, ",", TokenIdent}, kinds)
+	require.Equal(t, []string{"SELECT", "'a'", ",", "`b`"}, sources)
+	require.Equal(t, "a", tokens[1].String)
+	require.Equal(t, "b", tokens[3].String)
+
+	tokens, err = Tokenize("SELECT /* c */ 1", true)
+	require.NoError(t, err)
+	require.Equal(t, TokenComment, tokens[2].Kind)
+	require.Equal(t, "/* c */", tokens[2].String)
+	require.Equal(t, TokenWhitespace, tokens[3].Kind)
+
+	_, err = Tokenize("SELECT 'unclosed", true)
+	require.Error(t, err)
+}
@@ -158,13 +158,17 @@ func (p *Parser) parseProjectionOrderBy(pos Pos) (*ProjectionOrderBy, error) {
 	if err := p.consumeKeyword(KeywordBy); err != nil {
 		return nil, err
 	}
-	columns, err := p.parseColumnExprList(p.Pos())
+	orderBy, err := p.parseOrderByExprList(p.Pos())
 	if err != nil {
 		return nil, err
 	}
 	return &ProjectionOrderBy{
 		OrderByPos: pos,
-		Columns:    columns,
+		Columns: &ColumnExprList{
+			ListPos: orderBy.Pos(),
+			ListEnd: orderBy.End(),
+			Items:   orderBy.Items,
+		},
 	}, nil
 }
 
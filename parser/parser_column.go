@@ -13,7 +13,14 @@ func (p *Parser) tryParseColumnComment(pos Pos) (*StringLiteral, error) {
 }
 
 func (p *Parser) parseExpr(pos Pos) (Expr, error) {
-	orExpr, err := p.parseOrExpr(pos)
+	lambdaExpr, err := p.tryParseLambdaExpr(pos)
+	if err != nil {
+		return nil, err
+	}
+	if lambdaExpr != nil {
+		return lambdaExpr, nil
+	}
+	orExpr, err := p.parseExprWithPrecedence(pos, precedenceLowest)
 	if err != nil {
 		return orExpr, err
 	}
@@ -34,188 +41,426 @@ func (p *Parser) parseExpr(pos Pos) (Expr, error) {
 	return orExpr, nil
 }
 
-func (p *Parser) parseOrExpr(pos Pos) (Expr, error) {
-	expr, err := p.parseAndExpr(pos)
+// tryParseLambdaExpr parses x -> expr or (x, y) -> expr, the lambda has the lowest precedence so
+// the body extends as far as possible. It returns nil and leaves the tokens untouched if the input
+// doesn't start with the parameters of a lambda.
+func (p *Parser) tryParseLambdaExpr(pos Pos) (*LambdaExpr, error) {
+	if !p.matchTokenKind(TokenIdent) && !p.matchTokenKind("(") {
+		return nil, nil // nolint
+	}
+	lastToken, current := p.lexer.lastToken, p.lexer.current
+	lambdaExpr := p.tryParseLambdaParams(pos)
+	if lambdaExpr == nil {
+		p.lexer.lastToken, p.lexer.current = lastToken, current
+		return nil, nil // nolint
+	}
+	body, err := p.parseExpr(p.Pos())
 	if err != nil {
 		return nil, err
 	}
-	for {
-		if p.tryConsumeKeyword(KeywordOr) == nil {
-			return expr, nil
-		}
+	lambdaExpr.Body = body
+	return lambdaExpr, nil
+}
 
-		rightExpr, err := p.parseAndExpr(p.Pos())
+// tryParseLambdaParams consumes the parameters and the arrow of a lambda, it returns nil if they don't match.
+func (p *Parser) tryParseLambdaParams(pos Pos) *LambdaExpr {
+	lambdaExpr := &LambdaExpr{LeftParenPos: pos}
+	if p.matchTokenKind(TokenIdent) {
+		param, err := p.parseIdent()
 		if err != nil {
-			return nil, err
+			return nil
+		}
+		lambdaExpr.Params = append(lambdaExpr.Params, param)
+	} else {
+		if _, err := p.consumeTokenKind("("); err != nil {
+			return nil
 		}
-		expr = &BinaryExpr{
-			LeftExpr:  expr,
-			Operation: opTypeOr,
-			RightExpr: rightExpr,
+		for {
+			if !p.matchTokenKind(TokenIdent) {
+				return nil
+			}
+			param, err := p.parseIdent()
+			if err != nil {
+				return nil
+			}
+			lambdaExpr.Params = append(lambdaExpr.Params, param)
+			if p.tryConsumeTokenKind(",") == nil {
+				break
+			}
+		}
+		lambdaExpr.RightParenPos = p.Pos()
+		if _, err := p.consumeTokenKind(")"); err != nil {
+			return nil
+		}
+	}
+	if !p.matchTokenKind(opTypeArrow) {
+		return nil
+	}
+	lambdaExpr.ArrowPos = p.Pos()
+	if err := p.lexer.consumeToken(); err != nil {
+		return nil
+	}
+	return lambdaExpr
+}
+
+// binaryOperator is a binary operator which may consist of several tokens like GLOBAL NOT IN.
+type binaryOperator struct {
+	operation  TokenKind
+	hasNot     bool
+	hasGlobal  bool
+	precedence int
+	tokens     int
+}
+
+// peekBinaryOperator returns the binary operator starting from the last token without consuming it,
+// or nil if the last token doesn't start a binary operator.
+func (p *Parser) peekBinaryOperator() *binaryOperator {
+	last := p.last()
+	if last == nil {
+		return nil
+	}
+	op := &binaryOperator{operation: last.Kind, tokens: 1}
+	if (last.Kind == TokenKeyword || last.Kind == TokenIdent) && last.QuoteType == Unquoted {
+		op.operation = TokenKind(strings.ToUpper(last.String))
+		switch op.operation {
+		case KeywordGlobal, KeywordNot, KeywordIs:
+			next := p.peekTokens(3)
+			if op.operation == KeywordGlobal {
+				op.hasGlobal = true
+				if len(next) > 0 && isKeywordToken(next[0], KeywordNot) {
+					op.hasNot = true
+					next = next[1:]
+					op.tokens++
+				}
+				if len(next) == 0 || !isKeywordToken(next[0], KeywordIn) {
+					return nil
+				}
+				op.operation = opTypeIn
+				op.tokens++
+				break
+			}
+			if op.operation == KeywordNot {
+				if len(next) == 0 || !(isKeywordToken(next[0], KeywordIn) ||
+					isKeywordToken(next[0], KeywordLike) || isKeywordToken(next[0], KeywordIlike)) {
+					return nil
+				}
+				op.hasNot = true
+				op.operation = TokenKind(strings.ToUpper(next[0].String))
+				op.tokens++
+				break
+			}
+			// IS [NOT] DISTINCT FROM
+			op.operation = opTypeIsDistinctFrom
+			if len(next) > 0 && isKeywordToken(next[0], KeywordNot) {
+				op.operation = opTypeIsNotDistinctFrom
+				next = next[1:]
+				op.tokens++
+			}
+			if len(next) < 2 || !isKeywordToken(next[0], KeywordDistinct) || !isKeywordToken(next[1], KeywordFrom) {
+				return nil
+			}
+			op.tokens += 2
 		}
 	}
+	precedence, ok := binaryOperatorPrecedences[op.operation]
+	if !ok {
+		return nil
+	}
+	op.precedence = precedence
+	return op
 }
 
-func (p *Parser) parseAndExpr(pos Pos) (Expr, error) {
-	expr, err := p.parseNotExpr(pos)
+func isKeywordToken(token *Token, keyword string) bool {
+	return token.Kind == TokenKeyword && strings.EqualFold(token.String, keyword)
+}
+
+// parseExprWithPrecedence parses the expression consisting of the operators whose precedences are
+// at least minPrecedence. The binary operators are left-associative, and the ternary operator is
+// right-associative.
+func (p *Parser) parseExprWithPrecedence(_ Pos, minPrecedence int) (Expr, error) {
+	expr, err := p.parseUnaryExpr(p.Pos())
 	if err != nil {
 		return nil, err
 	}
 	for {
-		if p.tryConsumeKeyword(KeywordAnd) == nil {
-			return expr, nil
+		switch {
+		case minPrecedence <= precedenceIs && p.matchIsNull():
+			expr, err = p.parseIsNullExpr(expr)
+		case minPrecedence <= precedenceBetween && p.matchBetween():
+			expr, err = p.parseBetweenExpr(expr)
+		case minPrecedence <= precedenceTernary && p.matchTokenKind(opTypeQuery):
+			expr, err = p.parseTernaryExpr(expr)
+		default:
+			op := p.peekBinaryOperator()
+			if op == nil || op.precedence < minPrecedence {
+				return expr, nil
+			}
+			if op.operation == opTypeIn {
+				expr, err = p.parseInExpr(expr, op)
+			} else {
+				expr, err = p.parseBinaryExpr(expr, op)
+			}
 		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
 
-		rightExpr, err := p.parseNotExpr(p.Pos())
+func (p *Parser) parseBinaryExpr(leftExpr Expr, op *binaryOperator) (Expr, error) {
+	for i := 0; i < op.tokens; i++ {
+		_ = p.lexer.consumeToken()
+	}
+	if comparisonOperators.Contains(string(op.operation)) && p.matchQuantifiedSubQuery() {
+		quantifierPos := p.Pos()
+		quantifier := strings.ToUpper(p.last().String)
+		_ = p.lexer.consumeToken()
+		// the subquery is the right operand, so it takes the operators binding tighter than the comparison
+		subQuery, err := p.parseExprWithPrecedence(p.Pos(), op.precedence+1)
 		if err != nil {
 			return nil, err
 		}
-		expr = &BinaryExpr{
-			LeftExpr:  expr,
-			Operation: opTypeAnd,
-			RightExpr: rightExpr,
+		return &QuantifiedComparisonExpr{
+			Expr:          leftExpr,
+			Operation:     op.operation,
+			QuantifierPos: quantifierPos,
+			Quantifier:    quantifier,
+			SubQuery:      subQuery,
+		}, nil
+	}
+	rightExpr, err := p.parseExprWithPrecedence(p.Pos(), op.precedence+1)
+	if err != nil {
+		return nil, err
+	}
+	return &BinaryExpr{
+		LeftExpr:  leftExpr,
+		Operation: op.operation,
+		RightExpr: rightExpr,
+		HasGlobal: op.hasGlobal,
+		HasNot:    op.hasNot,
+	}, nil
+}
+
+// matchQuantifiedSubQuery reports whether the last token starts ANY (subquery) or ALL (subquery).
+func (p *Parser) matchQuantifiedSubQuery() bool {
+	return (p.matchKeyword(KeywordAny) || p.matchKeyword(KeywordAll)) && p.peekSubQuery()
+}
+
+// matchExists reports whether the last token starts EXISTS (subquery), exists(...) may still be a function call.
+func (p *Parser) matchExists() bool {
+	return p.matchKeyword(KeywordExists) && p.peekSubQuery()
+}
+
+// peekSubQuery reports whether the tokens after the last one are the start of a parenthesized query.
+func (p *Parser) peekSubQuery() bool {
+	return p.peekParenthesizedQuery(1)
+}
+
+// matchSubQuery reports whether the last token starts a parenthesized query.
+func (p *Parser) matchSubQuery() bool {
+	return p.peekParenthesizedQuery(0)
+}
+
+// peekParenthesizedQuery reports whether the tokens after the skipped ones are '(' followed by SELECT
+// or WITH. More '(' may come first since the first operand of a set operation may be parenthesized
+// as well like ((SELECT 1) UNION ALL (SELECT 2)).
+func (p *Parser) peekParenthesizedQuery(skip int) bool {
+	lastToken, current := p.lexer.lastToken, p.lexer.current
+	defer func() {
+		p.lexer.lastToken, p.lexer.current = lastToken, current
+	}()
+	for i := 0; i < skip; i++ {
+		if err := p.lexer.consumeToken(); err != nil {
+			return false
 		}
 	}
+	if !p.matchTokenKind("(") {
+		return false
+	}
+	for p.matchTokenKind("(") {
+		if err := p.lexer.consumeToken(); err != nil {
+			return false
+		}
+	}
+	return p.matchKeyword(KeywordSelect) || p.matchKeyword(KeywordWith)
 }
 
-func (p *Parser) parseNotExpr(pos Pos) (Expr, error) {
-	if p.tryConsumeKeyword(KeywordNot) == nil {
-		return p.parseIsOrNotNull(p.Pos())
+// tryParseSubQueryExpr parses the parenthesized query if the last token starts one. The expression
+// which only starts with a parenthesized query like ((SELECT 1) + 1) is left untouched, and nil is
+// returned for it.
+func (p *Parser) tryParseSubQueryExpr(pos Pos) (*SubQuery, error) {
+	if !p.matchSubQuery() {
+		return nil, nil
+	}
+	next := p.peekTokens(1)
+	nested := len(next) == 1 && next[0].Kind == "("
+	lastToken, current := p.lexer.lastToken, p.lexer.current
+	subQuery, err := p.parseSubQueryExpr(pos)
+	if err != nil && nested {
+		p.lexer.lastToken, p.lexer.current = lastToken, current
+		return nil, nil // nolint
 	}
+	return subQuery, err
+}
 
-	notExpr, err := p.parseNotExpr(p.Pos())
+// syntax: '(' selectQuery ')'
+func (p *Parser) parseSubQueryExpr(pos Pos) (*SubQuery, error) {
+	if _, err := p.consumeTokenKind("("); err != nil {
+		return nil, err
+	}
+	selectQuery, err := p.parseSelectQuery(p.Pos())
 	if err != nil {
 		return nil, err
 	}
-	return &NotExpr{
-		NotPos: pos,
-		Expr:   notExpr,
+	rightParenPos := p.Pos()
+	if _, err := p.consumeTokenKind(")"); err != nil {
+		return nil, err
+	}
+	return &SubQuery{
+		LeftParenPos:  pos,
+		RightParenPos: rightParenPos,
+		Select:        selectQuery,
 	}, nil
 }
 
-func (p *Parser) parseIsOrNotNull(pos Pos) (Expr, error) {
-	expr, err := p.parseCompareExpr(p.Pos())
+// syntax: expr [GLOBAL] [NOT] IN (subquery | table | values)
+func (p *Parser) parseInExpr(expr Expr, op *binaryOperator) (*InExpr, error) {
+	for i := 0; i < op.tokens-1; i++ {
+		_ = p.lexer.consumeToken()
+	}
+	inExpr := &InExpr{
+		Expr:   expr,
+		Global: op.hasGlobal,
+		Not:    op.hasNot,
+		InPos:  p.Pos(),
+	}
+	if err := p.consumeKeyword(KeywordIn); err != nil {
+		return nil, err
+	}
+	var err error
+	if inExpr.SubQuery, err = p.tryParseSubQueryExpr(p.Pos()); err != nil {
+		return nil, err
+	}
+	if inExpr.SubQuery != nil {
+		return inExpr, nil
+	}
+	switch {
+	case p.matchInTable():
+		inExpr.Table, err = p.parseTableIdentifier(p.Pos())
+	default:
+		inExpr.List, err = p.parseExprWithPrecedence(p.Pos(), op.precedence+1)
+	}
 	if err != nil {
 		return nil, err
 	}
-	if p.tryConsumeKeyword(KeywordIs) == nil {
-		return expr, nil
+	return inExpr, nil
+}
+
+// matchInTable reports whether the right side of IN is a table like db.t rather than an expression like f(x).
+func (p *Parser) matchInTable() bool {
+	if p.lastTokenKind() != TokenIdent {
+		return false
+	}
+	next := p.peekTokens(3)
+	if len(next) >= 2 && next[0].Kind == "." && next[1].Kind == TokenIdent {
+		next = next[2:]
+	}
+	if len(next) == 0 {
+		return true
+	}
+	switch next[0].Kind {
+	case "(", "[", ".", opTypeCast:
+		return false
+	}
+	return true
+}
+
+// matchIsNull reports whether the last token starts IS NULL or IS NOT NULL.
+func (p *Parser) matchIsNull() bool {
+	if !p.matchKeyword(KeywordIs) {
+		return false
+	}
+	next := p.peekTokens(2)
+	if len(next) > 0 && isKeywordToken(next[0], KeywordNot) {
+		next = next[1:]
 	}
+	return len(next) > 0 && isKeywordToken(next[0], KeywordNull)
+}
 
+func (p *Parser) parseIsNullExpr(expr Expr) (Expr, error) {
+	isPos := expr.Pos()
+	if err := p.consumeKeyword(KeywordIs); err != nil {
+		return nil, err
+	}
 	isNotNull := p.tryConsumeKeyword(KeywordNot) != nil
 	if err := p.consumeKeyword(KeywordNull); err != nil {
 		return nil, err
 	}
-
 	if isNotNull {
 		return &IsNotNullExpr{
-			IsPos: pos,
+			IsPos: isPos,
 			Expr:  expr,
 		}, nil
 	}
 	return &IsNullExpr{
-		IsPos: pos,
+		IsPos: isPos,
 		Expr:  expr,
 	}, nil
 }
 
-func (p *Parser) parseCompareExpr(pos Pos) (Expr, error) {
-	hasNot, hasGlobal := false, false
-	expr, err := p.parseAddSubExpr(pos)
-	if err != nil {
-		return nil, err
+// matchBetween reports whether the last token starts BETWEEN or NOT BETWEEN.
+func (p *Parser) matchBetween() bool {
+	if p.matchKeyword(KeywordBetween) {
+		return true
 	}
-	switch {
-	case p.matchTokenKind("["):
-		params, err := p.parseArrayParams(pos)
-		if err != nil {
-			return nil, err
-		}
-		return &ObjectParams{
-			Object: expr,
-			Params: params,
-		}, nil
-	case p.matchTokenKind(opTypeEQ):
-	case p.matchTokenKind(opTypeLT):
-	case p.matchTokenKind(opTypeLE):
-	case p.matchTokenKind(opTypeGE):
-	case p.matchTokenKind(opTypeGT):
-	case p.matchTokenKind(opTypeDoubleEQ):
-	case p.matchTokenKind(opTypeNE):
-	case p.matchTokenKind("<>"):
-	case p.matchTokenKind(opTypeQuery):
-	case p.matchKeyword(KeywordIn):
-	case p.matchKeyword(KeywordLike):
-	case p.matchKeyword(KeywordIlike):
-	case p.matchKeyword(KeywordGlobal):
-		_ = p.lexer.consumeToken()
-		hasGlobal = true
-	case p.matchKeyword(KeywordNot):
-		_ = p.lexer.consumeToken()
-		switch {
-		case p.matchKeyword(KeywordIn):
-		case p.matchKeyword(KeywordLike):
-		case p.matchKeyword(KeywordIlike):
-		default:
-			return nil, fmt.Errorf("expected IN, LIKE or ILIKE after NOT, got %s", p.lastTokenKind())
-		}
-		hasNot = true
-	default:
-		return expr, nil
+	if !p.matchKeyword(KeywordNot) {
+		return false
 	}
-	op := TokenKind(strings.ToUpper(p.last().String))
-	_ = p.lexer.consumeToken()
+	next := p.peekTokens(1)
+	return len(next) > 0 && isKeywordToken(next[0], KeywordBetween)
+}
 
-	rightExpr, err := p.parseAddSubExpr(p.Pos())
-	if err != nil {
+// syntax: expr [NOT] BETWEEN low AND high, the bounds bind tighter than BETWEEN so the AND isn't a logical AND.
+func (p *Parser) parseBetweenExpr(expr Expr) (*BetweenExpr, error) {
+	hasNot := p.tryConsumeKeyword(KeywordNot) != nil
+	betweenPos := p.Pos()
+	if err := p.consumeKeyword(KeywordBetween); err != nil {
 		return nil, err
 	}
-	return &BinaryExpr{
-		LeftExpr:  expr,
-		HasNot:    hasNot,
-		HasGlobal: hasGlobal,
-		Operation: op,
-		RightExpr: rightExpr,
-	}, nil
-}
-
-func (p *Parser) parseAddSubExpr(pos Pos) (Expr, error) {
-	expr, err := p.parseMulDivModExpr(pos)
+	low, err := p.parseExprWithPrecedence(p.Pos(), precedenceBetween+1)
 	if err != nil {
 		return nil, err
 	}
-	for {
-		switch {
-		case p.matchTokenKind(opTypePlus), p.matchTokenKind(opTypeMinus):
-			op := p.lastTokenKind()
-			_ = p.lexer.consumeToken()
-			rightExpr, err := p.parseMulDivModExpr(p.Pos())
-			if err != nil {
-				return nil, err
-			}
-			expr = &BinaryExpr{
-				LeftExpr:  expr,
-				Operation: op,
-				RightExpr: rightExpr,
-			}
-		default:
-			return expr, nil
-		}
+	andPos := p.Pos()
+	if err := p.consumeKeyword(KeywordAnd); err != nil {
+		return nil, err
+	}
+	high, err := p.parseExprWithPrecedence(p.Pos(), precedenceBetween+1)
+	if err != nil {
+		return nil, err
 	}
+	return &BetweenExpr{
+		Expr:       expr,
+		Not:        hasNot,
+		BetweenPos: betweenPos,
+		Low:        low,
+		AndPos:     andPos,
+		High:       high,
+	}, nil
 }
 
 func (p *Parser) parseTernaryExpr(condition Expr) (*TernaryExpr, error) {
-	if _, err := p.consumeTokenKind("?"); err != nil {
+	if _, err := p.consumeTokenKind(opTypeQuery); err != nil {
 		return nil, err
 	}
-	trueExpr, err := p.parseExpr(p.Pos())
+	trueExpr, err := p.parseExprWithPrecedence(p.Pos(), precedenceLowest)
 	if err != nil {
 		return nil, err
 	}
 	if _, err := p.consumeTokenKind(":"); err != nil {
 		return nil, err
 	}
-	falseExpr, err := p.parseExpr(p.Pos())
+	falseExpr, err := p.parseExprWithPrecedence(p.Pos(), precedenceTernary)
 	if err != nil {
 		return nil, err
 	}
@@ -226,37 +471,6 @@ func (p *Parser) parseTernaryExpr(condition Expr) (*TernaryExpr, error) {
 	}, nil
 }
 
-func (p *Parser) parseMulDivModExpr(pos Pos) (Expr, error) {
-	expr, err := p.parseUnaryExpr(pos)
-	if err != nil {
-		return nil, err
-	}
-	for {
-		switch {
-		case p.matchTokenKind(opTypeQuery):
-			return p.parseTernaryExpr(expr)
-		case p.matchTokenKind(opTypeMul),
-			p.matchTokenKind(opTypeDiv),
-			p.matchTokenKind(opTypeMod),
-			p.matchTokenKind(opTypeArrow),
-			p.matchTokenKind(opTypeCast):
-			op := p.lastTokenKind()
-			_ = p.lexer.consumeToken()
-			rightExpr, err := p.parseUnaryExpr(p.Pos())
-			if err != nil {
-				return nil, err
-			}
-			expr = &BinaryExpr{
-				LeftExpr:  expr,
-				Operation: op,
-				RightExpr: rightExpr,
-			}
-		default:
-			return expr, nil
-		}
-	}
-}
-
 func (p *Parser) parseColumnExtractExpr(pos Pos) (*ExtractExpr, error) {
 	if err := p.consumeKeyword(KeywordExtract); err != nil {
 		return nil, err
@@ -294,36 +508,98 @@ func (p *Parser) parseColumnExtractExpr(pos Pos) (*ExtractExpr, error) {
 	}, nil
 }
 
+// parseUnaryExpr parses the prefix operators. The operand of NOT extends to the operators of higher
+// precedences like NOT a = b, while the operand of the sign is the postfix expression like -arr[1].
 func (p *Parser) parseUnaryExpr(pos Pos) (Expr, error) {
-	kind := p.last()
 	switch {
-	case p.matchTokenKind(opTypePlus),
-		p.matchTokenKind(opTypeMinus),
-		p.matchKeyword(KeywordNot):
+	case p.matchKeyword(KeywordNot):
 		_ = p.lexer.consumeToken()
+		expr, err := p.parseExprWithPrecedence(p.Pos(), precedenceNot+1)
+		if err != nil {
+			return nil, err
+		}
+		return &NotExpr{
+			NotPos: pos,
+			Expr:   expr,
+		}, nil
+	case p.matchTokenKind(opTypePlus), p.matchTokenKind(opTypeMinus):
+		kind := p.lastTokenKind()
+		_ = p.lexer.consumeToken()
+		expr, err := p.parseUnaryExpr(p.Pos())
+		if err != nil {
+			return nil, err
+		}
+		return &UnaryExpr{
+			UnaryPos: pos,
+			Kind:     kind,
+			Expr:     expr,
+		}, nil
 	default:
-		return p.parseColumnExpr(pos)
+		return p.parsePostfixExpr(pos)
 	}
+}
 
-	var expr Expr
-	var err error
-	switch {
-	case p.matchTokenKind(TokenIdent),
-		p.matchTokenKind("("):
-		expr, err = p.parseExpr(p.Pos())
-	default:
-		expr, err = p.parseColumnExpr(p.Pos())
-	}
+// parsePostfixExpr parses the subscripts like arr[1], the tuple element access like t.1 and the cast
+// like x::String, which have the highest precedence and can be chained like m['k'][2].1::String
+func (p *Parser) parsePostfixExpr(pos Pos) (Expr, error) {
+	expr, err := p.parseColumnExpr(pos)
 	if err != nil {
 		return nil, err
 	}
-
-	return &UnaryExpr{
-		UnaryPos: pos,
-		Kind:     kind.Kind,
-		Expr:     expr,
-	}, nil
-
+	for {
+		switch {
+		case p.matchTokenKind(opTypeCast):
+			_ = p.lexer.consumeToken()
+			columnType, err := p.parseColumnType(p.Pos())
+			if err != nil {
+				return nil, err
+			}
+			expr = &BinaryExpr{
+				LeftExpr:  expr,
+				Operation: opTypeCast,
+				RightExpr: columnType,
+			}
+		case p.matchTokenKind("["):
+			leftBracketPos := p.Pos()
+			_ = p.lexer.consumeToken()
+			index, err := p.parseExpr(p.Pos())
+			if err != nil {
+				return nil, err
+			}
+			rightBracketPos := p.Pos()
+			if _, err := p.consumeTokenKind("]"); err != nil {
+				return nil, err
+			}
+			expr = &IndexExpr{
+				Object:          expr,
+				LeftBracketPos:  leftBracketPos,
+				RightBracketPos: rightBracketPos,
+				Index:           index,
+			}
+		case p.matchTokenKind("."):
+			dotPos := p.Pos()
+			_ = p.lexer.consumeToken()
+			var element Expr
+			switch {
+			case p.matchTokenKind(TokenInt):
+				element, err = p.parseNumber(p.Pos())
+			case p.matchTokenKind(TokenIdent):
+				element, err = p.parseIdent()
+			default:
+				err = p.expectedError(string(TokenInt), string(TokenIdent))
+			}
+			if err != nil {
+				return nil, err
+			}
+			expr = &TupleElementExpr{
+				Tuple:   expr,
+				DotPos:  dotPos,
+				Element: element,
+			}
+		default:
+			return expr, nil
+		}
+	}
 }
 
 func (p *Parser) parseColumnExpr(pos Pos) (Expr, error) { //nolint:funlen
@@ -345,6 +621,25 @@ func (p *Parser) parseColumnExpr(pos Pos) (Expr, error) { //nolint:funlen
 		return p.parseColumnCaseExpr(pos)
 	case p.matchKeyword(KeywordExtract):
 		return p.parseColumnExtractExpr(pos)
+	case p.matchInfOrNaN():
+		return p.parseInfOrNaN(pos)
+	case p.matchExists():
+		existsPos := p.Pos()
+		_ = p.lexer.consumeToken()
+		subQuery, err := p.parseSubQueryExpr(p.Pos())
+		if err != nil {
+			return nil, err
+		}
+		return &ExistsExpr{
+			ExistsPos: existsPos,
+			SubQuery:  subQuery,
+		}, nil
+	case p.matchColumnsMatcher():
+		matcher, err := p.parseColumnsMatcher(pos)
+		if err != nil {
+			return nil, err
+		}
+		return p.tryParseColumnTransformers(matcher)
 	case p.matchTokenKind(TokenIdent):
 		return p.parseIdentOrFunction(pos)
 	case p.matchTokenKind(TokenString): // string literal
@@ -353,17 +648,26 @@ func (p *Parser) parseColumnExpr(pos Pos) (Expr, error) { //nolint:funlen
 		p.matchTokenKind(TokenFloat): // number literal
 		return p.parseNumber(pos)
 	case p.matchTokenKind("("):
-		if peek, _ := p.lexer.peekToken(); peek != nil {
-			if peek.Kind == TokenKeyword && strings.EqualFold(peek.String, KeywordSelect) {
-				return p.parseSelectQuery(pos)
-			}
+		subQuery, err := p.tryParseSubQueryExpr(pos)
+		if err != nil {
+			return nil, err
+		}
+		if subQuery != nil {
+			return subQuery, nil
 		}
-		return p.parseFunctionParams(pos)
+		return p.parseTupleOrParenExpr(pos)
 	case p.matchTokenKind("*"):
-		return p.parseColumnStar(pos)
+		star, err := p.parseColumnStar(pos)
+		if err != nil {
+			return nil, err
+		}
+		return p.tryParseColumnTransformers(star)
 	case p.matchTokenKind("["):
 		return p.parseArrayParams(pos)
-
+	case p.matchQueryParameter():
+		return p.parseQueryParameter(pos)
+	case p.matchTokenKind("{"):
+		return p.parseMapLiteral(pos)
 	default:
 		return nil, fmt.Errorf("unexpected token kind: %s", p.lastTokenKind())
 	}
@@ -388,7 +692,8 @@ func (p *Parser) parseColumnCastExpr(pos Pos) (Expr, error) {
 		return nil, err
 	}
 
-	columnExpr, err := p.parseColumnExpr(p.Pos())
+	// the operand may be any expression except the alias, which would take AS of CAST(x AS T)
+	columnExpr, err := p.parseExprWithPrecedence(p.Pos(), precedenceLowest)
 	if err != nil {
 		return nil, err
 	}
@@ -570,6 +875,46 @@ func (p *Parser) parseFunctionParams(pos Pos) (*ParamExprList, error) {
 	return paramExprList, nil
 }
 
+// parseFunctionModifiers parses the modifiers following the arguments of the function call.
+// syntax: [RESPECT NULLS | IGNORE NULLS] [FILTER '(' WHERE expr ')']
+func (p *Parser) parseFunctionModifiers(funcExpr *FunctionExpr) error {
+	if p.matchUnquotedWord("RESPECT") || p.matchUnquotedWord("IGNORE") {
+		next := p.peekTokens(1)
+		if len(next) == 1 && isKeywordToken(next[0], KeywordNulls) {
+			funcExpr.Nulls = strings.ToUpper(p.last().String)
+			funcExpr.NullsPos = p.Pos()
+			funcExpr.NullsEnd = next[0].End
+			_ = p.lexer.consumeToken()
+			_ = p.lexer.consumeToken()
+		}
+	}
+	if p.matchUnquotedWord("FILTER") {
+		next := p.peekTokens(2)
+		if len(next) == 2 && next[0].Kind == "(" && isKeywordToken(next[1], KeywordWhere) {
+			filterPos := p.Pos()
+			_ = p.lexer.consumeToken()
+			_ = p.lexer.consumeToken()
+			wherePos := p.Pos()
+			_ = p.lexer.consumeToken()
+			expr, err := p.parseExpr(p.Pos())
+			if err != nil {
+				return err
+			}
+			rightParenPos := p.Pos()
+			if _, err := p.consumeTokenKind(")"); err != nil {
+				return err
+			}
+			funcExpr.Filter = &FilterExpr{
+				FilterPos:     filterPos,
+				WherePos:      wherePos,
+				RightParenPos: rightParenPos,
+				Expr:          expr,
+			}
+		}
+	}
+	return nil
+}
+
 func (p *Parser) parseArrayParams(pos Pos) (*ArrayParamList, error) {
 	if _, err := p.consumeTokenKind("["); err != nil {
 		return nil, err
@@ -589,6 +934,81 @@ func (p *Parser) parseArrayParams(pos Pos) (*ArrayParamList, error) {
 	}, nil
 }
 
+// parseTupleOrParenExpr parses a parenthesized expression like (a + b), or a tuple literal
+// like (1, 'x'), (x,) and () unless there is exactly one item without a trailing comma.
+func (p *Parser) parseTupleOrParenExpr(pos Pos) (Expr, error) {
+	if _, err := p.consumeTokenKind("("); err != nil {
+		return nil, err
+	}
+	items := make([]Expr, 0)
+	hasTrailingComma := false
+	for !p.matchTokenKind(")") {
+		item, err := p.parseExpr(p.Pos())
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		hasTrailingComma = p.tryConsumeTokenKind(",") != nil
+		if !hasTrailingComma {
+			break
+		}
+	}
+	rightParenPos := p.Pos()
+	if _, err := p.consumeTokenKind(")"); err != nil {
+		return nil, err
+	}
+	if len(items) == 1 && !hasTrailingComma {
+		return &ParamExprList{
+			LeftParenPos:  pos,
+			RightParenPos: rightParenPos,
+			Items: &ColumnExprList{
+				ListPos: items[0].Pos(),
+				ListEnd: items[0].End(),
+				Items:   items,
+			},
+		}, nil
+	}
+	return &TupleLiteral{
+		LeftParenPos:  pos,
+		RightParenPos: rightParenPos,
+		Items:         items,
+	}, nil
+}
+
+// syntax: '{' [expr ':' expr {',' expr ':' expr}] '}'
+func (p *Parser) parseMapLiteral(pos Pos) (*MapLiteral, error) {
+	if _, err := p.consumeTokenKind("{"); err != nil {
+		return nil, err
+	}
+	keyValues := make([]KeyValue, 0)
+	for !p.matchTokenKind("}") {
+		key, err := p.parseExpr(p.Pos())
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.consumeTokenKind(":"); err != nil {
+			return nil, err
+		}
+		value, err := p.parseExpr(p.Pos())
+		if err != nil {
+			return nil, err
+		}
+		keyValues = append(keyValues, KeyValue{Key: key, Value: value})
+		if p.tryConsumeTokenKind(",") == nil {
+			break
+		}
+	}
+	rightBracePos := p.Pos()
+	if _, err := p.consumeTokenKind("}"); err != nil {
+		return nil, err
+	}
+	return &MapLiteral{
+		LeftBracePos:  pos,
+		RightBracePos: rightBracePos,
+		KeyValues:     keyValues,
+	}, nil
+}
+
 func (p *Parser) parseColumnsExpr(pos Pos) (Expr, error) {
 	return p.parseExpr(pos)
 }
@@ -859,6 +1279,194 @@ func (p *Parser) parseColumnStar(pos Pos) (*Ident, error) {
 	}, nil
 }
 
+// matchColumnsMatcher reports whether the last token starts COLUMNS(...)
+func (p *Parser) matchColumnsMatcher() bool {
+	if !p.matchKeyword(KeywordColumns) {
+		return false
+	}
+	next := p.peekTokens(1)
+	return len(next) == 1 && next[0].Kind == "("
+}
+
+// syntax: COLUMNS '(' (regexp | columnExprList) ')'
+func (p *Parser) parseColumnsMatcher(pos Pos) (*ColumnsMatcher, error) {
+	if err := p.consumeKeyword(KeywordColumns); err != nil {
+		return nil, err
+	}
+	if _, err := p.consumeTokenKind("("); err != nil {
+		return nil, err
+	}
+	matcher := &ColumnsMatcher{ColumnsPos: pos}
+	var err error
+	if p.matchTokenKind(TokenString) {
+		matcher.Pattern, err = p.parseString(p.Pos())
+	} else {
+		matcher.Columns, err = p.parseColumnExprListWithRoundBracket(p.Pos())
+	}
+	if err != nil {
+		return nil, err
+	}
+	matcher.RightParenPos = p.Pos()
+	if _, err := p.consumeTokenKind(")"); err != nil {
+		return nil, err
+	}
+	return matcher, nil
+}
+
+// tryParseColumnTransformers parses the chain of APPLY, EXCEPT and REPLACE following the matched columns,
+// it returns the columns as they are if there is no transformer.
+func (p *Parser) tryParseColumnTransformers(columns Expr) (Expr, error) {
+	var transformers []Expr
+	for {
+		var transformer Expr
+		var err error
+		switch {
+		case p.matchUnquotedWord("APPLY"):
+			transformer, err = p.parseApplyTransformer(p.Pos())
+		case p.matchKeyword(KeywordExcept) && !p.peekSelect():
+			transformer, err = p.parseExceptTransformer(p.Pos())
+		case p.matchKeyword(KeywordReplace):
+			transformer, err = p.parseReplaceTransformer(p.Pos())
+		default:
+			if len(transformers) == 0 {
+				return columns, nil
+			}
+			return &ColumnTransformerExpr{
+				Columns:      columns,
+				Transformers: transformers,
+			}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		transformers = append(transformers, transformer)
+	}
+}
+
+// peekSelect reports whether the tokens after the last one start a query, so that the EXCEPT
+// is the set operation rather than the column transformer.
+func (p *Parser) peekSelect() bool {
+	next := p.peekTokens(2)
+	if len(next) > 0 && next[0].Kind == "(" {
+		next = next[1:]
+	}
+	return len(next) > 0 && (isKeywordToken(next[0], KeywordSelect) || isKeywordToken(next[0], KeywordWith))
+}
+
+// syntax: APPLY '(' (function | lambda) ')' | APPLY function
+func (p *Parser) parseApplyTransformer(pos Pos) (*ApplyTransformer, error) {
+	_ = p.lexer.consumeToken()
+	transformer := &ApplyTransformer{ApplyPos: pos}
+	if p.tryConsumeTokenKind("(") == nil {
+		fn, err := p.parseIdentOrFunction(p.Pos())
+		if err != nil {
+			return nil, err
+		}
+		transformer.Func = fn
+		transformer.StatementEnd = fn.End()
+		return transformer, nil
+	}
+	transformer.HasParen = true
+	fn, err := p.parseExpr(p.Pos())
+	if err != nil {
+		return nil, err
+	}
+	transformer.Func = fn
+	transformer.StatementEnd = p.Pos()
+	if _, err := p.consumeTokenKind(")"); err != nil {
+		return nil, err
+	}
+	return transformer, nil
+}
+
+// syntax: EXCEPT [STRICT] ('(' columnName [, columnName...] ')' | '(' regexp ')' | columnName | regexp)
+func (p *Parser) parseExceptTransformer(pos Pos) (*ExceptTransformer, error) {
+	if err := p.consumeKeyword(KeywordExcept); err != nil {
+		return nil, err
+	}
+	transformer := &ExceptTransformer{ExceptPos: pos}
+	if p.matchUnquotedWord("STRICT") {
+		transformer.Strict = true
+		_ = p.lexer.consumeToken()
+	}
+	transformer.HasParen = p.tryConsumeTokenKind("(") != nil
+	for {
+		var column Expr
+		var err error
+		if p.matchTokenKind(TokenString) {
+			column, err = p.parseString(p.Pos())
+		} else {
+			column, err = p.parseIdentOrFunction(p.Pos())
+		}
+		if err != nil {
+			return nil, err
+		}
+		transformer.Columns = append(transformer.Columns, column)
+		transformer.StatementEnd = column.End()
+		if !transformer.HasParen || p.tryConsumeTokenKind(",") == nil {
+			break
+		}
+	}
+	if transformer.HasParen {
+		transformer.StatementEnd = p.Pos()
+		if _, err := p.consumeTokenKind(")"); err != nil {
+			return nil, err
+		}
+	}
+	return transformer, nil
+}
+
+// syntax: REPLACE [STRICT] ('(' expr AS columnName [, expr AS columnName...] ')' | expr AS columnName)
+func (p *Parser) parseReplaceTransformer(pos Pos) (*ReplaceTransformer, error) {
+	if err := p.consumeKeyword(KeywordReplace); err != nil {
+		return nil, err
+	}
+	transformer := &ReplaceTransformer{ReplacePos: pos}
+	if p.matchUnquotedWord("STRICT") {
+		transformer.Strict = true
+		_ = p.lexer.consumeToken()
+	}
+	lastToken, current := p.lexer.lastToken, p.lexer.current
+	if p.tryConsumeTokenKind("(") != nil {
+		// (x + 1) AS x is a single replacement with the parenthesized expression
+		transformer.HasParen = true
+		replaces, err := p.parseReplaceItems(true)
+		if err == nil && p.matchTokenKind(")") {
+			transformer.Replaces = replaces
+			transformer.StatementEnd = p.Pos()
+			_ = p.lexer.consumeToken()
+			return transformer, nil
+		}
+		p.lexer.lastToken, p.lexer.current = lastToken, current
+		transformer.HasParen = false
+	}
+	replaces, err := p.parseReplaceItems(false)
+	if err != nil {
+		return nil, err
+	}
+	transformer.Replaces = replaces
+	transformer.StatementEnd = replaces[0].End()
+	return transformer, nil
+}
+
+func (p *Parser) parseReplaceItems(multiple bool) ([]*AliasExpr, error) {
+	var replaces []*AliasExpr
+	for {
+		expr, err := p.parseExpr(p.Pos())
+		if err != nil {
+			return nil, err
+		}
+		replace, ok := expr.(*AliasExpr)
+		if !ok {
+			return nil, fmt.Errorf("expected <expr> AS <column> in REPLACE, but got %q", expr.String(0))
+		}
+		replaces = append(replaces, replace)
+		if !multiple || p.tryConsumeTokenKind(",") == nil {
+			return replaces, nil
+		}
+	}
+}
+
 func (p *Parser) tryParseCompressionLevel(pos Pos) (*NumberLiteral, error) {
 	if p.tryConsumeTokenKind("(") == nil {
 		return nil, nil // nolint
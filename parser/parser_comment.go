@@ -0,0 +1,167 @@
+package parser
+
+import "strings"
+
+type commentable interface {
+	Expr
+	comments() *Comments
+}
+
+// commentHost is a node which is able to hold comments, along with the hosts nested in it.
+type commentHost struct {
+	node     commentable
+	children []*commentHost
+}
+
+// listItem is an item of ColumnExprList or TupleLiteral like a column of the select list, whose
+// comments are held by the list.
+type listItem struct {
+	Expr
+	list  *itemList
+	index int
+}
+
+// itemList is the items of a list along with the comments of the items.
+type itemList struct {
+	node         Expr
+	items        []Expr
+	itemComments *[]*Comments
+}
+
+// Pos returns the start of the item, which is the aliased expression rather than AS for AliasExpr.
+func (l *listItem) Pos() Pos {
+	if alias, ok := l.Expr.(*AliasExpr); ok {
+		return alias.Expr.Pos()
+	}
+	return l.Expr.Pos()
+}
+
+func (l *listItem) comments() *Comments {
+	itemComments := l.list.itemComments
+	for len(*itemComments) < len(l.list.items) {
+		*itemComments = append(*itemComments, nil)
+	}
+	if (*itemComments)[l.index] == nil {
+		(*itemComments)[l.index] = &Comments{}
+	}
+	return (*itemComments)[l.index]
+}
+
+type commentHostCollector struct {
+	DefaultASTVisitor
+	roots []*commentHost
+	stack []*commentHost
+	lists []*itemList // the lists being visited, the innermost one is the last
+}
+
+func (c *commentHostCollector) enter(expr Expr) {
+	if len(c.lists) > 0 {
+		list := c.lists[len(c.lists)-1]
+		for i, item := range list.items {
+			if item == expr {
+				c.push(&listItem{Expr: expr, list: list, index: i})
+				break
+			}
+		}
+	}
+	switch expr := expr.(type) {
+	case *ColumnExprList:
+		c.lists = append(c.lists, &itemList{node: expr, items: expr.Items, itemComments: &expr.ItemComments})
+	case *TupleLiteral:
+		c.lists = append(c.lists, &itemList{node: expr, items: expr.Items, itemComments: &expr.ItemComments})
+	}
+	if node, ok := expr.(commentable); ok {
+		c.push(node)
+	}
+}
+
+func (c *commentHostCollector) push(node commentable) {
+	host := &commentHost{node: node}
+	if len(c.stack) > 0 {
+		parent := c.stack[len(c.stack)-1]
+		parent.children = append(parent.children, host)
+	} else {
+		c.roots = append(c.roots, host)
+	}
+	c.stack = append(c.stack, host)
+}
+
+func (c *commentHostCollector) leave(expr Expr) {
+	if len(c.lists) > 0 && c.lists[len(c.lists)-1].node == expr {
+		c.lists = c.lists[:len(c.lists)-1]
+	}
+	if _, ok := expr.(commentable); ok {
+		c.stack = c.stack[:len(c.stack)-1]
+	}
+	if len(c.stack) > 0 {
+		if item, ok := c.stack[len(c.stack)-1].node.(*listItem); ok && item.Expr == expr {
+			c.stack = c.stack[:len(c.stack)-1]
+		}
+	}
+}
+
+// RetainComments makes the parser keep the comments of the input. The comments are attached
+// to the nearest statement, query clause, column definition or list item like a column of the
+// select list, and printed back by String in their original styles.
+func (p *Parser) RetainComments() *Parser {
+	p.lexer.retainComments = true
+	return p
+}
+
+// Comments returns all comments which have been scanned if the parser retains comments.
+func (p *Parser) Comments() []*Comment {
+	return p.lexer.comments
+}
+
+func (p *Parser) attachComments(statements []Expr) error {
+	collector := &commentHostCollector{}
+	for _, statement := range statements {
+		if err := statement.Accept(collector); err != nil {
+			return err
+		}
+	}
+	for _, comment := range p.lexer.comments {
+		p.attachComment(collector.roots, comment)
+	}
+	return nil
+}
+
+// attachComment attaches the comment to the innermost host containing it: as a trailing comment
+// of the nested host it follows on the same line, otherwise as a leading comment of the nested
+// host after it.
+func (p *Parser) attachComment(hosts []*commentHost, comment *Comment) {
+	var owner *commentHost
+	for {
+		var inner *commentHost
+		for _, host := range hosts {
+			if host.node.Pos() <= comment.Pos() && comment.End() <= host.node.End() {
+				inner = host
+				break
+			}
+		}
+		if inner == nil {
+			break
+		}
+		owner = inner
+		hosts = inner.children
+	}
+
+	var prev, next *commentHost
+	for _, host := range hosts {
+		if host.node.End() <= comment.Pos() {
+			prev = host
+		} else if next == nil && host.node.Pos() >= comment.End() {
+			next = host
+		}
+	}
+	switch {
+	case prev != nil && !strings.ContainsAny(p.lexer.input[prev.node.End():comment.Pos()], "\r\n"):
+		prev.node.comments().TrailingComments = append(prev.node.comments().TrailingComments, comment)
+	case next != nil:
+		next.node.comments().LeadingComments = append(next.node.comments().LeadingComments, comment)
+	case owner != nil:
+		owner.node.comments().TrailingComments = append(owner.node.comments().TrailingComments, comment)
+	case prev != nil:
+		prev.node.comments().TrailingComments = append(prev.node.comments().TrailingComments, comment)
+	}
+}
@@ -1,7 +1,6 @@
 package parser
 
 import (
-	"errors"
 	"fmt"
 	"strings"
 )
@@ -45,7 +44,7 @@ func (p *Parser) consumeTokenKind(kind TokenKind) (*Token, error) {
 	if lastToken := p.tryConsumeTokenKind(kind); lastToken != nil {
 		return lastToken, nil
 	}
-	return nil, fmt.Errorf("expected the last token kind is: %s, but got %s", kind, p.lastTokenKind())
+	return nil, p.expectedError(string(kind))
 }
 
 func (p *Parser) tryConsumeTokenKind(kind TokenKind) *Token {
@@ -61,9 +60,15 @@ func (p *Parser) matchKeyword(keyword string) bool {
 	return p.matchTokenKind(TokenKeyword) && strings.EqualFold(p.last().String, keyword)
 }
 
+// matchUnquotedWord reports whether the last token is the unquoted identifier like APPLY, which
+// acts as a keyword in some clauses but isn't reserved so it can still be used as a name.
+func (p *Parser) matchUnquotedWord(word string) bool {
+	return p.matchTokenKind(TokenIdent) && p.last().QuoteType == Unquoted && strings.EqualFold(p.last().String, word)
+}
+
 func (p *Parser) consumeKeyword(keyword string) error {
 	if !p.matchKeyword(keyword) {
-		return fmt.Errorf("expected keyword: %s, but got %s", keyword, p.lastTokenKind())
+		return p.expectedError(keyword)
 	}
 	_ = p.lexer.consumeToken()
 	return nil
@@ -79,6 +84,17 @@ func (p *Parser) tryConsumeKeyword(keyword string) *Token {
 }
 
 func (p *Parser) parseIdent() (*Ident, error) {
+	if p.matchTokenKind("{") {
+		param, err := p.parseQueryParameter(p.Pos())
+		if err != nil {
+			return nil, err
+		}
+		return &Ident{
+			NamePos:   param.Pos(),
+			NameEnd:   param.End(),
+			Parameter: param,
+		}, nil
+	}
 	lastToken, err := p.consumeTokenKind(TokenIdent)
 	if err != nil {
 		return nil, err
@@ -92,6 +108,63 @@ func (p *Parser) parseIdent() (*Ident, error) {
 	return ident, nil
 }
 
+// peekTokens returns up to n tokens following the last token without consuming them.
+func (p *Parser) peekTokens(n int) []*Token {
+	lastToken, current := p.lexer.lastToken, p.lexer.current
+	defer func() {
+		p.lexer.lastToken, p.lexer.current = lastToken, current
+	}()
+	tokens := make([]*Token, 0, n)
+	for len(tokens) < n {
+		if err := p.lexer.consumeToken(); err != nil || p.last() == nil {
+			break
+		}
+		tokens = append(tokens, p.last())
+	}
+	return tokens
+}
+
+// matchQueryParameter reports whether the '{' starts a query parameter like {name:Type},
+// which is told apart from a map literal like {'a': 1} by the name followed by ':'.
+func (p *Parser) matchQueryParameter() bool {
+	if !p.matchTokenKind("{") {
+		return false
+	}
+	next := p.peekTokens(2)
+	return len(next) == 2 && (next[0].Kind == TokenIdent || next[0].Kind == TokenKeyword) && next[1].Kind == ":"
+}
+
+// syntax: '{' ident ':' columnType '}'
+func (p *Parser) parseQueryParameter(pos Pos) (*QueryParameterExpr, error) {
+	if _, err := p.consumeTokenKind("{"); err != nil {
+		return nil, err
+	}
+	if !p.matchTokenKind(TokenIdent) {
+		return nil, fmt.Errorf("expected query parameter name, but got %s", p.lastTokenKind())
+	}
+	name, err := p.parseIdent()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.consumeTokenKind(":"); err != nil {
+		return nil, err
+	}
+	columnType, err := p.parseColumnType(p.Pos())
+	if err != nil {
+		return nil, err
+	}
+	rightBracePos := p.Pos()
+	if _, err := p.consumeTokenKind("}"); err != nil {
+		return nil, err
+	}
+	return &QueryParameterExpr{
+		LBracePos: pos,
+		RBracePos: rightBracePos,
+		Name:      name,
+		Type:      columnType,
+	}, nil
+}
+
 func (p *Parser) parseIdentOrStar() (*Ident, error) {
 	switch {
 	case p.matchTokenKind(TokenIdent):
@@ -219,6 +292,33 @@ func (p *Parser) parseNumber(pos Pos) (*NumberLiteral, error) {
 	return number, nil
 }
 
+// matchInfOrNaN checks if the last token is a bare inf, infinity or nan rather than a function or column name.
+func (p *Parser) matchInfOrNaN() bool {
+	if !p.matchTokenKind(TokenIdent) || p.last().QuoteType != Unquoted {
+		return false
+	}
+	switch strings.ToLower(p.last().String) {
+	case "inf", "infinity", "nan":
+	default:
+		return false
+	}
+	if next, err := p.lexer.peekToken(); err == nil && next != nil && (next.Kind == "(" || next.Kind == ".") {
+		return false
+	}
+	return true
+}
+
+func (p *Parser) parseInfOrNaN(pos Pos) (*NumberLiteral, error) {
+	lastToken := p.last()
+	_ = p.lexer.consumeToken()
+	return &NumberLiteral{
+		NumPos:  pos,
+		NumEnd:  lastToken.End,
+		Literal: lastToken.String,
+		Base:    10,
+	}, nil
+}
+
 func (p *Parser) parseString(pos Pos) (*StringLiteral, error) {
 	lastToken, err := p.consumeTokenKind(TokenString)
 	if err != nil {
@@ -228,6 +328,12 @@ func (p *Parser) parseString(pos Pos) (*StringLiteral, error) {
 		LiteralPos: pos,
 		LiteralEnd: lastToken.End,
 		Literal:    lastToken.String,
+		Raw:        lastToken.String,
+		QuoteType:  lastToken.QuoteType,
+		HeredocTag: lastToken.HeredocTag,
+	}
+	if str.QuoteType != Heredoc {
+		str.Literal = unescapeString(lastToken.String)
 	}
 	return str, nil
 }
@@ -242,7 +348,7 @@ func (p *Parser) parseLiteral(pos Pos) (Literal, error) {
 		// accept the NULL keyword
 		return &NullLiteral{NullPos: pos}, nil
 	default:
-		return nil, fmt.Errorf("expected <int>, <string> or keyword <NULL>, but got %q", p.last().Kind)
+		return nil, p.expectedError(string(TokenInt), string(TokenString), KeywordNull)
 	}
 }
 
@@ -287,40 +393,6 @@ func (p *Parser) parseFormatExpr(pos Pos) (*FormatExpr, error) {
 	}, nil
 }
 
-func (p *Parser) wrapError(err error) error {
-	if err == nil {
-		return nil
-	}
-
-	lineNo := 0
-	column := 0
-
-	for i := 0; i < int(p.Pos()); i++ {
-		if p.lexer.input[i] == '\n' {
-			lineNo++
-			column = 0
-		} else {
-			column++
-		}
-	}
-
-	lines := strings.Split(p.lexer.input, "\n")
-	var buf strings.Builder
-	buf.WriteString(fmt.Sprintf("line %d:%d %s\n", lineNo, column, err.Error()))
-	for i, line := range lines {
-		if i == lineNo {
-			buf.WriteString(line)
-			buf.WriteByte('\n')
-			for j := 0; j < column; j++ {
-				buf.WriteByte(' ')
-			}
-			buf.WriteString(strings.Repeat("^", len(p.lexer.lastToken.String)))
-			buf.WriteByte('\n')
-		}
-	}
-	return errors.New(buf.String())
-}
-
 func (p *Parser) parseRatioExpr(pos Pos) (*RatioExpr, error) {
 	numerator, err := p.parseNumber(pos)
 	if err != nil {
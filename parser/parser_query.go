@@ -3,6 +3,7 @@ package parser
 import (
 	"errors"
 	"fmt"
+	"strings"
 )
 
 func (p *Parser) tryParseWithExpr(pos Pos) (*WithExpr, error) {
@@ -187,7 +188,7 @@ func (p *Parser) parseJoinOp(_ Pos) []string {
 
 func (p *Parser) parseJoinTableExpr(_ Pos) (Expr, error) {
 	switch {
-	case p.matchTokenKind(TokenIdent), p.matchTokenKind("("):
+	case p.matchTokenKind(TokenIdent), p.matchTokenKind("("), p.matchTokenKind("{"):
 		tableExpr, err := p.parseTableExpr(p.Pos())
 		if err != nil {
 			return nil, err
@@ -214,7 +215,7 @@ func (p *Parser) parseJoinTableExpr(_ Pos) (Expr, error) {
 			StatementEnd: statementEnd,
 		}, nil
 	default:
-		return nil, fmt.Errorf("expected table name or subquery, got %s", p.last().Kind)
+		return nil, p.expectedError(string(TokenIdent), "(")
 	}
 }
 
@@ -286,7 +287,7 @@ func (p *Parser) parseTableExpr(pos Pos) (*TableExpr, error) {
 	var expr Expr
 	var err error
 	switch {
-	case p.matchTokenKind(TokenString), p.matchTokenKind(TokenIdent):
+	case p.matchTokenKind(TokenString), p.matchTokenKind(TokenIdent), p.matchTokenKind("{"):
 		// table name
 		tableIdentifier, err := p.parseTableIdentifier(p.Pos())
 		if err != nil {
@@ -399,7 +400,8 @@ func (p *Parser) tryParseGroupByExpr(pos Pos) (*GroupByExpr, error) {
 	return p.parseGroupByExpr(pos)
 }
 
-// syntax: groupByClause? (WITH (CUBE | ROLLUP))? (WITH TOTALS)?
+// syntax: GROUP BY (ALL | ROLLUP(columnExprList) | CUBE(columnExprList) | GROUPING SETS (groupingSet, ...) | columnExprList)
+// (WITH (CUBE | ROLLUP))? (WITH TOTALS)?
 func (p *Parser) parseGroupByExpr(pos Pos) (*GroupByExpr, error) {
 	if err := p.consumeKeyword(KeywordGroup); err != nil {
 		return nil, err
@@ -408,50 +410,175 @@ func (p *Parser) parseGroupByExpr(pos Pos) (*GroupByExpr, error) {
 		return nil, err
 	}
 
-	var expr Expr
+	groupByExpr := &GroupByExpr{GroupByPos: pos}
 	var err error
-	aggregateType := ""
-	if p.matchKeyword(KeywordCube) || p.matchKeyword(KeywordRollup) {
-		aggregateType = p.last().String
+	switch {
+	case p.matchKeyword(KeywordAll):
+		groupByExpr.All = true
+		groupByExpr.StatementEnd = p.last().End
 		_ = p.lexer.consumeToken()
-		expr, err = p.parseFunctionParams(p.Pos())
-	} else {
-		expr, err = p.parseColumnExprListWithRoundBracket(p.Pos())
+	case p.matchKeyword(KeywordRollup):
+		groupByExpr.Expr, err = p.parseRollupExpr(p.Pos())
+	case p.matchKeyword(KeywordCube):
+		groupByExpr.Expr, err = p.parseCubeExpr(p.Pos())
+	case p.matchGroupingSets():
+		groupByExpr.Expr, err = p.parseGroupingSetsExpr(p.Pos())
+	default:
+		groupByExpr.Expr, err = p.parseColumnExprListWithRoundBracket(p.Pos())
 	}
 	if err != nil {
 		return nil, err
 	}
-
-	groupByExpr := &GroupByExpr{
-		GroupByPos:    pos,
-		AggregateType: aggregateType,
-		Expr:          expr,
+	if groupByExpr.Expr != nil {
+		groupByExpr.StatementEnd = groupByExpr.Expr.End()
 	}
 
 	// parse WITH CUBE, ROLLUP, TOTALS
-	for p.tryConsumeKeyword(KeywordWith) != nil {
+	for p.matchKeyword(KeywordWith) {
+		_ = p.lexer.consumeToken()
 		switch {
-		case p.tryConsumeKeyword(KeywordCube) != nil:
+		case p.matchKeyword(KeywordCube):
 			groupByExpr.WithCube = true
-		case p.tryConsumeKeyword(KeywordRollup) != nil:
+		case p.matchKeyword(KeywordRollup):
 			groupByExpr.WithRollup = true
-		case p.tryConsumeKeyword(KeywordTotals) != nil:
+		case p.matchKeyword(KeywordTotals):
 			groupByExpr.WithTotals = true
 		default:
-			return nil, fmt.Errorf("expected CUBE, ROLLUP or TOTALS, got %s", p.lastTokenKind())
+			return nil, p.expectedError(KeywordCube, KeywordRollup, KeywordTotals)
 		}
+		groupByExpr.StatementEnd = p.last().End
+		_ = p.lexer.consumeToken()
 	}
 
 	return groupByExpr, nil
 }
 
+// parseGroupingKeys parses the parenthesized keys of ROLLUP and CUBE, and returns the position of ')'
+func (p *Parser) parseGroupingKeys() (*ColumnExprList, Pos, error) {
+	if _, err := p.consumeTokenKind("("); err != nil {
+		return nil, 0, err
+	}
+	items, err := p.parseColumnExprListWithRoundBracket(p.Pos())
+	if err != nil {
+		return nil, 0, err
+	}
+	rightParenPos := p.Pos()
+	if _, err := p.consumeTokenKind(")"); err != nil {
+		return nil, 0, err
+	}
+	return items, rightParenPos, nil
+}
+
+// syntax: ROLLUP '(' columnExprList ')'
+func (p *Parser) parseRollupExpr(pos Pos) (*RollupExpr, error) {
+	if err := p.consumeKeyword(KeywordRollup); err != nil {
+		return nil, err
+	}
+	items, rightParenPos, err := p.parseGroupingKeys()
+	if err != nil {
+		return nil, err
+	}
+	return &RollupExpr{
+		RollupPos:     pos,
+		RightParenPos: rightParenPos,
+		Items:         items,
+	}, nil
+}
+
+// syntax: CUBE '(' columnExprList ')'
+func (p *Parser) parseCubeExpr(pos Pos) (*CubeExpr, error) {
+	if err := p.consumeKeyword(KeywordCube); err != nil {
+		return nil, err
+	}
+	items, rightParenPos, err := p.parseGroupingKeys()
+	if err != nil {
+		return nil, err
+	}
+	return &CubeExpr{
+		CubePos:       pos,
+		RightParenPos: rightParenPos,
+		Items:         items,
+	}, nil
+}
+
+// matchGroupingSets reports whether the last token starts GROUPING SETS, GROUPING isn't reserved
+// since grouping() is a function.
+func (p *Parser) matchGroupingSets() bool {
+	if !p.matchUnquotedWord("GROUPING") {
+		return false
+	}
+	next := p.peekTokens(1)
+	return len(next) == 1 && next[0].QuoteType == Unquoted && strings.EqualFold(next[0].String, "SETS")
+}
+
+// syntax: GROUPING SETS '(' groupingSet [, groupingSet...] ')'
+func (p *Parser) parseGroupingSetsExpr(pos Pos) (*GroupingSetsExpr, error) {
+	// GROUPING SETS
+	_ = p.lexer.consumeToken()
+	_ = p.lexer.consumeToken()
+	if _, err := p.consumeTokenKind("("); err != nil {
+		return nil, err
+	}
+	groupingSets := &GroupingSetsExpr{GroupingPos: pos}
+	for !p.matchTokenKind(")") {
+		set, err := p.parseGroupingSet(p.Pos())
+		if err != nil {
+			return nil, err
+		}
+		groupingSets.Sets = append(groupingSets.Sets, set)
+		if p.tryConsumeTokenKind(",") == nil {
+			break
+		}
+	}
+	groupingSets.RightParenPos = p.Pos()
+	if _, err := p.consumeTokenKind(")"); err != nil {
+		return nil, err
+	}
+	return groupingSets, nil
+}
+
+// syntax: '(' [columnExpr [, columnExpr...]] ')' | columnExpr
+func (p *Parser) parseGroupingSet(pos Pos) (*GroupingSet, error) {
+	if p.tryConsumeTokenKind("(") == nil {
+		expr, err := p.parseExpr(pos)
+		if err != nil {
+			return nil, err
+		}
+		return &GroupingSet{
+			LeftParenPos:  expr.Pos(),
+			RightParenPos: expr.End(),
+			Items:         []Expr{expr},
+		}, nil
+	}
+	set := &GroupingSet{LeftParenPos: pos}
+	for !p.matchTokenKind(")") {
+		expr, err := p.parseExpr(p.Pos())
+		if err != nil {
+			return nil, err
+		}
+		set.Items = append(set.Items, expr)
+		if p.tryConsumeTokenKind(",") == nil {
+			break
+		}
+	}
+	set.RightParenPos = p.Pos()
+	if _, err := p.consumeTokenKind(")"); err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
 func (p *Parser) tryParseLimitExpr(pos Pos) (*LimitExpr, error) {
-	if !p.matchKeyword(KeywordLimit) {
-		return nil, nil
+	switch {
+	case p.matchKeyword(KeywordLimit):
+		return p.parseLimitExpr(pos)
+	case p.matchKeyword(KeywordOffset):
+		return p.parseOffsetFetchExpr(pos)
 	}
-	return p.parseLimitExpr(pos)
+	return nil, nil
 }
 
+// syntax: LIMIT (limit | offset, limit) [OFFSET offset] [WITH TIES]
 func (p *Parser) parseLimitExpr(pos Pos) (*LimitExpr, error) {
 	if err := p.consumeKeyword(KeywordLimit); err != nil {
 		return nil, err
@@ -473,11 +600,82 @@ func (p *Parser) parseLimitExpr(pos Pos) (*LimitExpr, error) {
 		return nil, err
 	}
 
-	return &LimitExpr{
+	limitExpr := &LimitExpr{
 		LimitPos: pos,
 		Limit:    limit,
 		Offset:   offset,
-	}, nil
+	}
+	limitExpr.StatementEnd = limit.End()
+	if offset != nil && offset.End() > limitExpr.StatementEnd {
+		limitExpr.StatementEnd = offset.End()
+	}
+	if p.matchWithTies() {
+		_ = p.lexer.consumeToken()
+		limitExpr.WithTies = true
+		limitExpr.StatementEnd = p.last().End
+		_ = p.lexer.consumeToken()
+	}
+	return limitExpr, nil
+}
+
+// matchWithTies reports whether the last tokens are WITH TIES
+func (p *Parser) matchWithTies() bool {
+	if !p.matchKeyword(KeywordWith) {
+		return false
+	}
+	next := p.peekTokens(1)
+	return len(next) == 1 && isKeywordToken(next[0], KeywordTies)
+}
+
+// syntax: OFFSET offset [ROW | ROWS] [FETCH (FIRST | NEXT) limit (ROW | ROWS) (ONLY | WITH TIES)]
+func (p *Parser) parseOffsetFetchExpr(pos Pos) (*LimitExpr, error) {
+	if err := p.consumeKeyword(KeywordOffset); err != nil {
+		return nil, err
+	}
+	offset, err := p.parseExpr(p.Pos())
+	if err != nil {
+		return nil, err
+	}
+	limitExpr := &LimitExpr{
+		LimitPos:     pos,
+		StatementEnd: offset.End(),
+		Offset:       offset,
+	}
+	if p.matchKeyword(KeywordRow) || p.matchKeyword(KeywordRows) {
+		limitExpr.OffsetRows = strings.ToUpper(p.last().String)
+		limitExpr.StatementEnd = p.last().End
+		_ = p.lexer.consumeToken()
+	}
+	if !p.matchUnquotedWord("FETCH") {
+		return limitExpr, nil
+	}
+	_ = p.lexer.consumeToken()
+	if !p.matchKeyword(KeywordFirst) && !p.matchUnquotedWord("NEXT") {
+		return nil, p.expectedError(KeywordFirst, "NEXT")
+	}
+	limitExpr.Fetch = strings.ToUpper(p.last().String)
+	_ = p.lexer.consumeToken()
+	if limitExpr.Limit, err = p.parseExpr(p.Pos()); err != nil {
+		return nil, err
+	}
+	if !p.matchKeyword(KeywordRow) && !p.matchKeyword(KeywordRows) {
+		return nil, p.expectedError(KeywordRow, KeywordRows)
+	}
+	limitExpr.FetchRows = strings.ToUpper(p.last().String)
+	_ = p.lexer.consumeToken()
+	switch {
+	case p.matchUnquotedWord("ONLY"):
+		limitExpr.StatementEnd = p.last().End
+		_ = p.lexer.consumeToken()
+	case p.matchWithTies():
+		_ = p.lexer.consumeToken()
+		limitExpr.WithTies = true
+		limitExpr.StatementEnd = p.last().End
+		_ = p.lexer.consumeToken()
+	default:
+		return nil, p.expectedError("ONLY", KeywordWith+" "+KeywordTies)
+	}
+	return limitExpr, nil
 }
 
 func (p *Parser) tryParseLimitByExpr(pos Pos) (Expr, error) {
@@ -493,8 +691,9 @@ func (p *Parser) parseLimitByExpr(pos Pos) (Expr, error) {
 		return nil, err
 	}
 
+	// LIMIT n WITH TIES can't be followed by BY, which is left as an unexpected token
 	var by *ColumnExprList
-	if p.tryConsumeKeyword(KeywordBy) == nil {
+	if limitExpr.WithTies || p.tryConsumeKeyword(KeywordBy) == nil {
 		return limitExpr, nil
 	}
 	if by, err = p.parseColumnExprListWithRoundBracket(p.Pos()); err != nil {
@@ -721,6 +920,29 @@ func (p *Parser) parseHavingExpr(pos Pos) (*HavingExpr, error) {
 	}, nil
 }
 
+func (p *Parser) tryParseQualifyExpr(pos Pos) (*QualifyExpr, error) {
+	if !p.matchKeyword(KeywordQualify) {
+		return nil, nil
+	}
+	return p.parseQualifyExpr(pos)
+}
+
+func (p *Parser) parseQualifyExpr(pos Pos) (*QualifyExpr, error) {
+	if err := p.consumeKeyword(KeywordQualify); err != nil {
+		return nil, err
+	}
+
+	expr, err := p.parseColumnsExpr(p.Pos())
+	if err != nil {
+		return nil, err
+	}
+
+	return &QualifyExpr{
+		QualifyPos: pos,
+		Expr:       expr,
+	}, nil
+}
+
 func (p *Parser) parseSubQuery(pos Pos) (*SubQueryExpr, error) {
 	if err := p.consumeKeyword(KeywordAs); err != nil {
 		return nil, err
@@ -739,45 +961,86 @@ func (p *Parser) parseSubQuery(pos Pos) (*SubQueryExpr, error) {
 
 func (p *Parser) parseSelectQuery(_ Pos) (*SelectQuery, error) {
 	if !p.matchKeyword(KeywordSelect) && !p.matchKeyword(KeywordWith) && !p.matchTokenKind("(") {
-		return nil, fmt.Errorf("expected SELECT, WITH or (, got %s", p.lastTokenKind())
+		return nil, p.expectedError(KeywordSelect, KeywordWith, "(")
+	}
+	expr, err := p.parseSetOperationExpr(0)
+	if err != nil {
+		return nil, err
+	}
+	if setOperation, ok := expr.(*SetOperationExpr); ok {
+		return &SelectQuery{
+			SelectPos:    setOperation.Pos(),
+			StatementEnd: setOperation.End(),
+			SetOperation: setOperation,
+		}, nil
 	}
+	query := expr.(*SelectQuery)
+	// the parentheses around the whole query belong to the enclosing expression like FROM (SELECT ...)
+	query.HasParen = false
+	return query, nil
+}
 
-	hasParen := p.tryConsumeTokenKind("(") != nil
-	selectExpr, err := p.parseSelectStatement(p.Pos())
+// parseSetOperationExpr parses the queries combined by the set operations whose precedences are
+// at least minPrecedence, the set operations are left-associative.
+// syntax: setOperand ((UNION | EXCEPT | INTERSECT) [ALL | DISTINCT] setOperand)*
+func (p *Parser) parseSetOperationExpr(minPrecedence int) (Expr, error) {
+	left, err := p.parseSetOperand()
 	if err != nil {
 		return nil, err
 	}
-	switch {
-	case p.tryConsumeKeyword(KeywordUnion) != nil:
-		switch {
-		case p.tryConsumeKeyword(KeywordAll) != nil:
-			unionAllExpr, err := p.parseSelectStatement(p.Pos())
-			if err != nil {
-				return nil, err
-			}
-			selectExpr.UnionAll = unionAllExpr
-		case p.tryConsumeKeyword(KeywordDistinct) != nil:
-			unionDistinctExpr, err := p.parseSelectStatement(p.Pos())
-			if err != nil {
-				return nil, err
-			}
-			selectExpr.UnionDistinct = unionDistinctExpr
-		default:
-			return nil, fmt.Errorf("expected ALL or DISTINCT, got %s", p.lastTokenKind())
+	for {
+		operator := p.peekSetOperator()
+		precedence := setOperationPrecedences[operator]
+		if operator == "" || precedence < minPrecedence {
+			return left, nil
 		}
-	case p.tryConsumeKeyword(KeywordExcept) != nil:
-		exceptExpr, err := p.parseSelectStatement(p.Pos())
+		operatorPos := p.Pos()
+		_ = p.lexer.consumeToken()
+		var modifier string
+		if p.matchKeyword(KeywordAll) || p.matchKeyword(KeywordDistinct) {
+			modifier = strings.ToUpper(p.last().String)
+			_ = p.lexer.consumeToken()
+		}
+		right, err := p.parseSetOperationExpr(precedence + 1)
 		if err != nil {
 			return nil, err
 		}
-		selectExpr.Except = exceptExpr
+		left = &SetOperationExpr{
+			Left:        left,
+			OperatorPos: operatorPos,
+			Operator:    operator,
+			Modifier:    modifier,
+			Right:       right,
+		}
 	}
-	if hasParen {
-		if _, err := p.consumeTokenKind(")"); err != nil {
-			return nil, err
+}
+
+// peekSetOperator returns the set operation of the last token, or empty if it's not a set operation.
+func (p *Parser) peekSetOperator() string {
+	for _, operator := range []string{KeywordUnion, KeywordExcept, KeywordIntersect} {
+		if p.matchKeyword(operator) {
+			return operator
 		}
 	}
-	return selectExpr, nil
+	return ""
+}
+
+// syntax: selectStatement | '(' selectQuery ')'
+func (p *Parser) parseSetOperand() (Expr, error) {
+	if p.tryConsumeTokenKind("(") == nil {
+		return p.parseSelectStatement(p.Pos())
+	}
+	expr, err := p.parseSetOperationExpr(0)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.consumeTokenKind(")"); err != nil {
+		return nil, err
+	}
+	if query, ok := expr.(*SelectQuery); ok {
+		query.HasParen = true
+	}
+	return expr, nil
 }
 
 func (p *Parser) parseSelectStatement(pos Pos) (*SelectQuery, error) { // nolint: funlen
@@ -860,6 +1123,13 @@ func (p *Parser) parseSelectStatement(pos Pos) (*SelectQuery, error) { // nolint
 	if havingExpr != nil {
 		statementEnd = havingExpr.End()
 	}
+	qualifyExpr, err := p.tryParseQualifyExpr(p.Pos())
+	if err != nil {
+		return nil, err
+	}
+	if qualifyExpr != nil {
+		statementEnd = qualifyExpr.End()
+	}
 	orderByExpr, err := p.tryParseOrderByExprList(p.Pos())
 	if err != nil {
 		return nil, err
@@ -876,18 +1146,18 @@ func (p *Parser) parseSelectStatement(pos Pos) (*SelectQuery, error) { // nolint
 	}
 	if parsedLimitBy != nil {
 		statementEnd = parsedLimitBy.End()
-		switch e := parsedLimitBy.(type) {
-		case *LimitByExpr:
-			limitByExpr = e
-			limitExpr, err = p.tryParseLimitExpr(p.Pos())
-			if err != nil {
-				return nil, err
-			}
-			if limitExpr != nil {
-				statementEnd = limitExpr.End()
-			}
-		case *LimitExpr:
-			limitExpr = e
+	}
+	if e, ok := parsedLimitBy.(*LimitExpr); ok {
+		limitExpr = e
+	} else {
+		limitByExpr, _ = parsedLimitBy.(*LimitByExpr)
+		// LIMIT or OFFSET after LIMIT BY, or OFFSET without LIMIT
+		limitExpr, err = p.tryParseLimitExpr(p.Pos())
+		if err != nil {
+			return nil, err
+		}
+		if limitExpr != nil {
+			statementEnd = limitExpr.End()
 		}
 	}
 
@@ -912,6 +1182,7 @@ func (p *Parser) parseSelectStatement(pos Pos) (*SelectQuery, error) { // nolint
 		Where:         whereExpr,
 		GroupBy:       groupByExpr,
 		Having:        havingExpr,
+		Qualify:       qualifyExpr,
 		OrderBy:       orderByExpr,
 		LimitBy:       limitByExpr,
 		Limit:         limitExpr,
@@ -921,7 +1192,7 @@ func (p *Parser) parseSelectStatement(pos Pos) (*SelectQuery, error) { // nolint
 }
 
 func (p *Parser) parseCTEExpr(pos Pos) (*CTEExpr, error) {
-	expr, err := p.parseOrExpr(pos)
+	expr, err := p.parseExprWithPrecedence(pos, precedenceLowest)
 	if err != nil {
 		return nil, err
 	}
@@ -2,6 +2,8 @@ package parser
 
 import (
 	"fmt"
+	"strings"
+	"unicode/utf8"
 )
 
 func (p *Parser) parseDDL(pos Pos) (DDL, error) {
@@ -30,8 +32,8 @@ func (p *Parser) parseDDL(pos Pos) (DDL, error) {
 		case p.matchKeyword(KeywordRow):
 		case p.matchKeyword(KeywordSettings):
 		default:
-			return nil, fmt.Errorf("expected keyword: DATABASE|TABLE|VIEW|DICTIONARY|FUNCTION|ROW|QUOTA|SETTINGS, but got %q",
-				p.last().String)
+			return nil, p.expectedError(KeywordDatabase, KeywordTable, KeywordTemporary, KeywordFunction,
+				KeywordMaterialized, KeywordLive, KeywordView, KeywordRole)
 		}
 	case p.matchKeyword(KeywordAlter):
 		_ = p.lexer.consumeToken()
@@ -41,7 +43,7 @@ func (p *Parser) parseDDL(pos Pos) (DDL, error) {
 		case p.matchKeyword(KeywordTable):
 			return p.parseAlterTable(pos)
 		default:
-			return nil, fmt.Errorf("expected keyword: TABLE|ROLE, but got %q", p.last().String)
+			return nil, p.expectedError(KeywordTable, KeywordRole)
 		}
 	case p.matchKeyword(KeywordDrop),
 		p.matchKeyword(KeywordDetach):
@@ -58,7 +60,7 @@ func (p *Parser) parseDDL(pos Pos) (DDL, error) {
 			p.matchKeyword(KeywordRole):
 			return p.parserDropUserOrRole(pos)
 		default:
-			return nil, fmt.Errorf("expected keyword: DATABASE|TABLE, but got %q", p.last().String)
+			return nil, p.expectedError(KeywordDatabase, KeywordTemporary, KeywordView, KeywordDictionary, KeywordTable, KeywordUser, KeywordRole)
 		}
 	case p.matchKeyword(KeywordTruncate):
 		return p.parseTruncateTable(pos)
@@ -175,15 +177,6 @@ func (p *Parser) parseIdentOrFunction(_ Pos) (Expr, error) {
 		return nil, err
 	}
 	switch {
-	case p.matchTokenKind("["):
-		params, err := p.parseArrayParams(p.Pos())
-		if err != nil {
-			return nil, err
-		}
-		return &ObjectParams{
-			Object: ident,
-			Params: params,
-		}, nil
 	case p.matchTokenKind("("):
 		params, err := p.parseFunctionParams(p.Pos())
 		if err != nil {
@@ -193,6 +186,9 @@ func (p *Parser) parseIdentOrFunction(_ Pos) (Expr, error) {
 			Name:   ident,
 			Params: params,
 		}
+		if err := p.parseFunctionModifiers(funcExpr); err != nil {
+			return nil, err
+		}
 		if overToken := p.tryConsumeKeyword(KeywordOver); overToken != nil {
 			var overExpr Expr
 			switch {
@@ -204,7 +200,7 @@ func (p *Parser) parseIdentOrFunction(_ Pos) (Expr, error) {
 					return nil, err
 				}
 			default:
-				return nil, fmt.Errorf("expected IDENT or (, but got %q", p.lastTokenKind())
+				return nil, p.expectedError(string(TokenIdent), "(")
 			}
 
 			if err != nil {
@@ -217,41 +213,104 @@ func (p *Parser) parseIdentOrFunction(_ Pos) (Expr, error) {
 			}, nil
 		}
 		return funcExpr, nil
-	case p.tryConsumeTokenKind(".") != nil:
+	case p.matchTokenKind("."):
+		return p.parseQualifiedName(ident)
+	}
+	return ident, nil
+}
+
+// parseQualifiedName parses the names following the first one like t.column, db.t.column, t.* and db.t.*,
+// or the subcolumn path of the JSON column like data.user.id.:Int64 and data.^user. It stops before
+// the dot of a tuple element like t.1, which is left to parsePostfixExpr.
+func (p *Parser) parseQualifiedName(ident *Ident) (Expr, error) {
+	names := []*Ident{ident}
+	path := &JSONPathExpr{}
+loop:
+	for p.matchTokenKind(".") {
+		next := p.peekTokens(2)
+		if len(next) == 0 {
+			break
+		}
 		switch {
-		case p.matchTokenKind(TokenIdent):
-			nextIdent, err := p.parseIdent()
+		case next[0].Kind == "*" && path.SubObjectPos == 0:
+			_ = p.lexer.consumeToken()
+			star, err := p.parseColumnStar(p.Pos())
 			if err != nil {
 				return nil, err
 			}
-			if p.tryConsumeTokenKind(".") != nil {
-				thirdIdent, err := p.parseIdent()
-				if err != nil {
-					return nil, err
+			var columns Expr
+			switch len(names) {
+			case 1:
+				columns = &NestedIdentifier{
+					Ident:    ident,
+					DotIdent: star,
 				}
-				return &ColumnIdentifier{
-					Database: ident,
-					Table:    nextIdent,
-					Column:   thirdIdent,
-				}, nil
+			case 2:
+				columns = &ColumnIdentifier{
+					Database: names[0],
+					Table:    names[1],
+					Column:   star,
+				}
+			default:
+				path.Names = append(names, star)
+				columns = path
 			}
-			return &ColumnIdentifier{
-				Table:  ident,
-				Column: nextIdent,
-			}, nil
-		case p.matchTokenKind("*"):
-			nextIdent, err := p.parseColumnStar(p.Pos())
+			return p.tryParseColumnTransformers(columns)
+		case next[0].Kind == TokenIdent || next[0].Kind == TokenKeyword:
+			_ = p.lexer.consumeToken()
+			names = append(names, p.parseNameToken())
+		case next[0].Kind == "^" && path.SubObjectPos == 0 && len(next) == 2 &&
+			(next[1].Kind == TokenIdent || next[1].Kind == TokenKeyword):
+			_ = p.lexer.consumeToken()
+			path.SubObjectPos = p.Pos()
+			path.SubObjectIndex = len(names)
+			_ = p.lexer.consumeToken()
+			names = append(names, p.parseNameToken())
+		case next[0].Kind == ":":
+			_ = p.lexer.consumeToken()
+			_ = p.lexer.consumeToken()
+			typeHint, err := p.parseColumnType(p.Pos())
 			if err != nil {
 				return nil, err
 			}
-			return &NestedIdentifier{
-				Ident:    ident,
-				DotIdent: nextIdent,
+			path.TypeHint = typeHint
+			break loop
+		default:
+			break loop
+		}
+	}
+	if path.SubObjectPos == 0 && path.TypeHint == nil && len(names) <= 3 {
+		switch len(names) {
+		case 1:
+			return ident, nil
+		case 2:
+			return &ColumnIdentifier{
+				Table:  names[0],
+				Column: names[1],
+			}, nil
+		default:
+			return &ColumnIdentifier{
+				Database: names[0],
+				Table:    names[1],
+				Column:   names[2],
 			}, nil
 		}
+	}
+	path.Names = names
+	return path, nil
+}
 
+// parseNameToken parses the name after the dot of a qualified name, where the keywords are
+// allowed as names like data.order.id
+func (p *Parser) parseNameToken() *Ident {
+	lastToken := p.last()
+	_ = p.lexer.consumeToken()
+	return &Ident{
+		NamePos:   lastToken.Pos,
+		NameEnd:   lastToken.End,
+		Name:      lastToken.String,
+		QuoteType: lastToken.QuoteType,
 	}
-	return ident, nil
 }
 
 func (p *Parser) parseTableIdentifier(_ Pos) (*TableIdentifier, error) {
@@ -501,7 +560,7 @@ func (p *Parser) parseTableArgExpr(pos Pos) (Expr, error) {
 	case p.matchTokenKind(TokenInt), p.matchTokenKind(TokenString), p.matchKeyword("NULL"):
 		return p.parseLiteral(p.Pos())
 	default:
-		return nil, fmt.Errorf("unexpected token: %q, expected <Ident>, <literal>", p.last().String)
+		return nil, p.expectedError(string(TokenIdent), string(TokenInt), string(TokenString), KeywordNull)
 	}
 }
 
@@ -550,7 +609,7 @@ func (p *Parser) tryParseOnCluster(pos Pos) (*OnClusterExpr, error) {
 	case p.matchTokenKind(TokenString):
 		expr, err = p.parseString(p.Pos())
 	default:
-		return nil, fmt.Errorf("unexpected token: %q, expected <IDENT> or <STRING>", p.last().String)
+		return nil, p.expectedError(string(TokenIdent), string(TokenString))
 	}
 	if err != nil {
 		return nil, err
@@ -633,6 +692,14 @@ func (p *Parser) parseOrderByExprList(pos Pos) (*OrderByListExpr, error) {
 		orderByListExpr.ListEnd = items[len(items)-1].End()
 	}
 	orderByListExpr.Items = items
+	if p.matchUnquotedWord("INTERPOLATE") {
+		interpolate, err := p.parseInterpolateExpr(p.Pos())
+		if err != nil {
+			return nil, err
+		}
+		orderByListExpr.Interpolate = interpolate
+		orderByListExpr.ListEnd = interpolate.End()
+	}
 	return orderByListExpr, nil
 }
 
@@ -652,11 +719,109 @@ func (p *Parser) parseOrderByExpr(pos Pos) (*OrderByExpr, error) {
 		direction = OrderDirectionDesc
 		_ = p.lexer.consumeToken()
 	}
-	return &OrderByExpr{
+	orderByExpr := &OrderByExpr{
 		OrderPos:  pos,
 		Expr:      columnExpr,
 		Direction: direction,
-	}, nil
+	}
+	if p.matchKeyword(KeywordNulls) {
+		_ = p.lexer.consumeToken()
+		if !p.matchKeyword(KeywordFirst) && !p.matchKeyword(KeywordLast) {
+			return nil, p.expectedError(KeywordFirst, KeywordLast)
+		}
+		orderByExpr.Nulls = strings.ToUpper(p.last().String)
+		orderByExpr.NullsEnd = p.last().End
+		_ = p.lexer.consumeToken()
+	}
+	if p.tryConsumeKeyword(KeywordCollate) != nil {
+		collate, err := p.parseString(p.Pos())
+		if err != nil {
+			return nil, err
+		}
+		orderByExpr.Collate = collate
+	}
+	if p.matchKeyword(KeywordWith) {
+		if next := p.peekTokens(1); len(next) == 1 && next[0].Kind == TokenIdent &&
+			next[0].QuoteType == Unquoted && strings.EqualFold(next[0].String, "FILL") {
+			withFill, err := p.parseWithFillExpr(p.Pos())
+			if err != nil {
+				return nil, err
+			}
+			orderByExpr.WithFill = withFill
+		}
+	}
+	return orderByExpr, nil
+}
+
+// syntax: WITH FILL [FROM expr] [TO expr] [STEP expr] [STALENESS expr]
+func (p *Parser) parseWithFillExpr(pos Pos) (*WithFillExpr, error) {
+	if err := p.consumeKeyword(KeywordWith); err != nil {
+		return nil, err
+	}
+	withFill := &WithFillExpr{WithPos: pos, StatementEnd: p.last().End}
+	_ = p.lexer.consumeToken() // FILL
+	parseClause := func(expr *Expr) error {
+		_ = p.lexer.consumeToken()
+		clauseExpr, err := p.parseExpr(p.Pos())
+		if err != nil {
+			return err
+		}
+		*expr = clauseExpr
+		withFill.StatementEnd = clauseExpr.End()
+		return nil
+	}
+	if p.matchKeyword(KeywordFrom) {
+		if err := parseClause(&withFill.From); err != nil {
+			return nil, err
+		}
+	}
+	if p.matchKeyword(KeywordTo) {
+		if err := parseClause(&withFill.To); err != nil {
+			return nil, err
+		}
+	}
+	if p.matchUnquotedWord("STEP") {
+		if err := parseClause(&withFill.Step); err != nil {
+			return nil, err
+		}
+	}
+	if p.matchUnquotedWord("STALENESS") {
+		if err := parseClause(&withFill.Staleness); err != nil {
+			return nil, err
+		}
+	}
+	return withFill, nil
+}
+
+// syntax: INTERPOLATE ['(' column [AS expr] [, column [AS expr]...] ')']
+func (p *Parser) parseInterpolateExpr(pos Pos) (*InterpolateExpr, error) {
+	interpolate := &InterpolateExpr{InterpolatePos: pos, StatementEnd: p.last().End}
+	_ = p.lexer.consumeToken()
+	if p.tryConsumeTokenKind("(") == nil {
+		return interpolate, nil
+	}
+	for !p.matchTokenKind(")") {
+		column, err := p.parseIdent()
+		if err != nil {
+			return nil, err
+		}
+		item := &InterpolateItem{Column: column}
+		if p.tryConsumeKeyword(KeywordAs) != nil {
+			item.Expr, err = p.parseExpr(p.Pos())
+			if err != nil {
+				return nil, err
+			}
+		}
+		interpolate.Items = append(interpolate.Items, item)
+		if p.tryConsumeTokenKind(",") == nil {
+			break
+		}
+	}
+	interpolate.StatementEnd = p.Pos()
+	if _, err := p.consumeTokenKind(")"); err != nil {
+		return nil, err
+	}
+	return interpolate, nil
 }
 
 func (p *Parser) tryParseTTLExprList(pos Pos) (*TTLExprList, error) {
@@ -709,7 +874,7 @@ func (p *Parser) parseTTLExpr(pos Pos) (*TTLExpr, error) {
 				return nil, err
 			}
 		} else {
-			return nil, fmt.Errorf("expected keyword <DISK> or <VOLUME>, but got %q", p.last().String)
+			return nil, p.expectedError(KeywordDisk, KeywordVolume)
 		}
 	}
 	return &TTLExpr{
@@ -785,14 +950,27 @@ func (p *Parser) parseSettingsExpr(pos Pos) (*SettingsExpr, error) {
 			return nil, err
 		}
 		expr = number
+	case p.matchTokenKind(TokenFloat):
+		number, err := p.parseNumber(p.Pos())
+		if err != nil {
+			return nil, err
+		}
+		expr = number
 	case p.matchTokenKind(TokenString):
 		str, err := p.parseString(p.Pos())
 		expr = str
 		if err != nil {
 			return nil, err
 		}
+	case p.matchTokenKind("{"), p.matchTokenKind("["), p.matchTokenKind("("):
+		// map, array or tuple literal, or query parameter
+		literal, err := p.parseColumnExpr(p.Pos())
+		if err != nil {
+			return nil, err
+		}
+		expr = literal
 	default:
-		return nil, fmt.Errorf("unexpected token: %q, expected <number> or <string>", p.last().String)
+		return nil, p.expectedError(string(TokenInt), string(TokenFloat), string(TokenString), "{", "[", "(")
 	}
 
 	return &SettingsExpr{
@@ -935,7 +1113,7 @@ func (p *Parser) parseStatement(pos Pos) (Expr, error) {
 		p.matchKeyword(KeywordTruncate),
 		p.matchKeyword(KeywordRename):
 		expr, err = p.parseDDL(pos)
-	case p.matchKeyword(KeywordSelect), p.matchKeyword(KeywordWith):
+	case p.matchKeyword(KeywordSelect), p.matchKeyword(KeywordWith), p.matchTokenKind("("):
 		expr, err = p.parseSelectQuery(pos)
 	case p.matchKeyword(KeywordDelete):
 		expr, err = p.parseDeleteFrom(pos)
@@ -956,7 +1134,11 @@ func (p *Parser) parseStatement(pos Pos) (Expr, error) {
 	case p.matchKeyword(KeywordGrant):
 		expr, err = p.parseGrantPrivilege(pos)
 	default:
-		return nil, fmt.Errorf("unexpected token: %q", p.last().String)
+		return nil, p.expectedError(
+			KeywordCreate, KeywordAttach, KeywordAlter, KeywordDrop, KeywordDetach, KeywordTruncate,
+			KeywordRename, KeywordSelect, KeywordWith, KeywordDelete, KeywordInsert, KeywordUse,
+			KeywordSet, KeywordSystem, KeywordOptimize, KeywordCheck, KeywordExplain, KeywordGrant,
+		)
 	}
 	if err != nil {
 		return nil, err
@@ -968,28 +1150,170 @@ func (p *Parser) parseStatement(pos Pos) (Expr, error) {
 
 	// Statement can be terminated by ';' or EOF
 	if p.last() != nil && !p.matchTokenKind(";") {
-		return nil, fmt.Errorf("<EOF> or ';' was expected, but got: %q", p.last().String)
+		return nil, p.expectedError(string(TokenEOF), ";")
 	}
 	return expr, nil
 }
 
 func (p *Parser) ParseStatements() ([]Expr, error) {
+	statements, parseErrors := p.parseStatements(false)
+	if len(parseErrors) > 0 {
+		return nil, parseErrors[0]
+	}
+	return statements, nil
+}
+
+// ParseStatementsWithRecovery keeps parsing after a statement fails to parse: the rest of the
+// statement up to the next ';' outside of brackets is skipped, and the error is recorded along
+// with the statement span. It returns the statements which are parsed successfully and the errors.
+func (p *Parser) ParseStatementsWithRecovery() ([]Expr, []*ParseError) {
+	return p.parseStatements(true)
+}
+
+// ParseExpr parses the whole input as a single expression, e.g. a filter condition.
+func (p *Parser) ParseExpr() (Expr, error) {
+	return p.parseWholeInput(p.parseExpr)
+}
+
+// ParseColumnType parses the whole input as a column type, e.g. Map(String, Array(Nullable(UInt8))).
+func (p *Parser) ParseColumnType() (Expr, error) {
+	return p.parseWholeInput(p.parseColumnType)
+}
+
+// ParseSelectQuery parses the whole input as a SELECT query, an optional trailing ';' is allowed.
+func (p *Parser) ParseSelectQuery() (*SelectQuery, error) {
+	expr, err := p.parseWholeInput(func(pos Pos) (Expr, error) {
+		selectQuery, err := p.parseSelectQuery(pos)
+		if err != nil {
+			return nil, err
+		}
+		_ = p.tryConsumeTokenKind(";")
+		return selectQuery, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return expr.(*SelectQuery), nil
+}
+
+// ParseTableIdentifier parses the whole input as a table identifier, e.g. db.table.
+func (p *Parser) ParseTableIdentifier() (*TableIdentifier, error) {
+	expr, err := p.parseWholeInput(func(pos Pos) (Expr, error) {
+		tableIdentifier, err := p.parseTableIdentifier(pos)
+		if err != nil {
+			return nil, err
+		}
+		return tableIdentifier, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return expr.(*TableIdentifier), nil
+}
+
+// parseWholeInput parses the input with parse and fails if any token is left behind.
+func (p *Parser) parseWholeInput(parse func(pos Pos) (Expr, error)) (Expr, error) {
+	var expr Expr
+	err := p.lexer.consumeToken()
+	if err == nil {
+		expr, err = parse(p.Pos())
+	}
+	if err == nil && p.last() != nil {
+		err = p.expectedError(string(TokenEOF))
+	}
+	if err == nil && p.lexer.retainComments {
+		err = p.attachComments([]Expr{expr})
+	}
+	if err != nil {
+		parseError := p.newParseError(err, 0)
+		parseError.StatementEnd = len(p.lexer.input)
+		return nil, parseError
+	}
+	return expr, nil
+}
+
+func (p *Parser) parseStatements(recovery bool) ([]Expr, []*ParseError) {
 	var statements []Expr
+	var parseErrors []*ParseError
 	for {
-		_ = p.lexer.consumeToken()
-		if p.lexer.isEOF() {
+		err := p.lexer.consumeToken()
+		if err == nil && p.last() == nil {
 			break
 		}
-		if p.matchTokenKind(";") {
+		if err == nil && p.matchTokenKind(";") {
 			continue
 		}
-		statement, err := p.parseStatement(p.Pos())
+		statementPos := p.lexer.current
+		var statement Expr
+		if err == nil {
+			statementPos = int(p.Pos())
+			statement, err = p.parseStatement(p.Pos())
+		}
 		if err != nil {
-			return nil, p.wrapError(err)
+			parseError := p.newParseError(err, len(statements)+len(parseErrors))
+			p.skipStatement(statementPos)
+			parseError.StatementStart = statementPos
+			parseError.StatementEnd = p.lexer.current
+			if p.last() != nil {
+				parseError.StatementEnd = int(p.last().Pos)
+			}
+			parseErrors = append(parseErrors, parseError)
+			if !recovery {
+				return nil, parseErrors
+			}
+			continue
 		}
 		statements = append(statements, statement)
 	}
-	return statements, nil
+	if p.lexer.retainComments {
+		if err := p.attachComments(statements); err != nil {
+			return nil, append(parseErrors, p.newParseError(err, len(statements)))
+		}
+	}
+	return statements, parseErrors
+}
+
+// skipStatement skips the tokens up to the next ';' which isn't enclosed in brackets, or to the end of input.
+func (p *Parser) skipStatement(statementPos int) {
+	// count the brackets which are still open before the last token
+	end := p.lexer.current
+	if p.last() != nil {
+		end, _ = tokenRange(*p.last())
+	}
+	depth := 0
+	lexer := NewLexer(p.lexer.input[statementPos:end])
+	for token, err := lexer.Next(); err == nil && token.Kind != TokenEOF; token, err = lexer.Next() {
+		switch token.Kind {
+		case "(", "[", "{":
+			depth++
+		case ")", "]", "}":
+			if depth > 0 {
+				depth--
+			}
+		}
+	}
+	for {
+		switch {
+		case p.last() == nil:
+			if p.lexer.isEOF() {
+				return
+			}
+			// skip the character which failed to lex
+			_, size := utf8.DecodeRuneInString(p.lexer.input[p.lexer.current:])
+			p.lexer.skipN(size)
+		case p.matchTokenKind("("), p.matchTokenKind("["), p.matchTokenKind("{"):
+			depth++
+		case p.matchTokenKind(")"), p.matchTokenKind("]"), p.matchTokenKind("}"):
+			if depth > 0 {
+				depth--
+			}
+		case p.matchTokenKind(";"):
+			if depth == 0 {
+				return
+			}
+		}
+		_ = p.lexer.consumeToken()
+	}
 }
 
 func (p *Parser) parseUseStatement(pos Pos) (*UseExpr, error) {
@@ -1095,10 +1419,10 @@ func (p *Parser) parseColumnNamesExpr(pos Pos) (*ColumnNamesExpr, error) {
 		if err != nil {
 			return nil, err
 		}
+		columnNames = append(columnNames, *name)
 		if p.tryConsumeTokenKind(",") == nil {
 			break
 		}
-		columnNames = append(columnNames, *name)
 	}
 	rightParenPos := p.Pos()
 	if _, err := p.consumeTokenKind(")"); err != nil {
@@ -1120,12 +1444,7 @@ func (p *Parser) parseValuesExpr(pos Pos) (*ValuesExpr, error) {
 	var err error
 	values := make([]Expr, 0)
 	for !p.lexer.isEOF() && p.tryConsumeTokenKind(")") == nil {
-		switch {
-		case p.matchTokenKind("("):
-			value, err = p.parseValuesExpr(p.Pos())
-		default:
-			value, err = p.parseExpr(p.Pos())
-		}
+		value, err = p.parseExpr(p.Pos())
 		if err != nil {
 			return nil, err
 		}
@@ -2,7 +2,9 @@ package parser
 
 import (
 	"encoding/json"
+	"errors"
 	"flag"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -110,3 +112,454 @@ func TestParser_Format(t *testing.T) {
 		}
 	}
 }
+
+func TestParser_RetainComments(t *testing.T) {
+	dir := "./testdata/comment"
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+		t.Run(entry.Name(), func(t *testing.T) {
+			fileBytes, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+			require.NoError(t, err)
+			parser := NewParser(string(fileBytes)).RetainComments()
+			stmts, err := parser.ParseStatements()
+			require.NoError(t, err)
+			outputBytes, _ := json.MarshalIndent(stmts, "", "  ")
+			g := goldie.New(t,
+				goldie.WithNameSuffix(".golden.json"),
+				goldie.WithDiffEngine(goldie.ColoredDiff),
+				goldie.WithFixtureDir(dir+"/output"))
+			g.Assert(t, entry.Name(), outputBytes)
+
+			var builder strings.Builder
+			for _, stmt := range stmts {
+				builder.WriteString(stmt.String(0))
+				builder.WriteByte(';')
+				builder.WriteByte('\n')
+			}
+			formatted := builder.String()
+			g = goldie.New(t,
+				goldie.WithNameSuffix(""),
+				goldie.WithDiffEngine(goldie.ColoredDiff),
+				goldie.WithFixtureDir(dir+"/format"))
+			g.Assert(t, entry.Name(), []byte(formatted))
+
+			// the formatted SQL must keep every comment
+			formattedParser := NewParser(formatted).RetainComments()
+			_, err = formattedParser.ParseStatements()
+			require.NoError(t, err)
+			require.Len(t, formattedParser.Comments(), len(parser.Comments()))
+			for i, comment := range parser.Comments() {
+				require.Equal(t, comment.Text, formattedParser.Comments()[i].Text)
+			}
+		})
+	}
+}
+
+func TestParser_ParseError(t *testing.T) {
+	tests := []struct {
+		sql            string
+		line, column   int
+		offset         int
+		token          string
+		expected       []string
+		statementIndex int
+		pretty         string
+	}{
+		{
+			sql:      "SELECT a FROM t GROUP x",
+			line:     1,
+			column:   23,
+			offset:   22,
+			token:    "x",
+			expected: []string{KeywordBy},
+			pretty:   "line 1:23 expected BY, but got \"x\"\nSELECT a FROM t GROUP x\n                      ^\n",
+		},
+		{
+			sql:            "SELECT 1;\nSELECT 名字 FROM t\nWHERE a = 1 'x'",
+			line:           3,
+			column:         13,
+			offset:         43,
+			token:          "x",
+			expected:       []string{string(TokenEOF), ";"},
+			statementIndex: 1,
+			pretty:         "line 3:13 expected <eof> or ;, but got \"x\"\nWHERE a = 1 'x'\n            ^^^\n",
+		},
+		{
+			sql:      "SELECT * FROM",
+			line:     1,
+			column:   14,
+			offset:   13,
+			expected: []string{string(TokenIdent), "("},
+			pretty:   "line 1:14 expected <ident> or (, but got <eof>\nSELECT * FROM\n             ^\n",
+		},
+	}
+	for _, tt := range tests {
+		parser := NewParser(tt.sql)
+		_, err := parser.ParseStatements()
+		require.Error(t, err)
+		var parseError *ParseError
+		require.True(t, errors.As(err, &parseError))
+		require.Equal(t, tt.line, parseError.Line)
+		require.Equal(t, tt.column, parseError.Column)
+		require.Equal(t, tt.offset, parseError.Offset)
+		require.Equal(t, tt.token, parseError.Token.String)
+		require.Equal(t, tt.expected, parseError.Expected)
+		require.Equal(t, tt.statementIndex, parseError.StatementIndex)
+		require.Equal(t, tt.pretty, parseError.Pretty())
+		require.True(t, strings.HasPrefix(parseError.Error(), fmt.Sprintf("line %d:%d ", tt.line, tt.column)))
+	}
+}
+
+func TestParser_ExpectedErrors(t *testing.T) {
+	tests := map[string][]string{
+		"CREATE INDEX i ON t (a)":                              {KeywordDatabase, KeywordTable, KeywordTemporary, KeywordFunction, KeywordMaterialized, KeywordLive, KeywordView, KeywordRole},
+		"SELECT count() OVER 1 FROM t":                         {string(TokenIdent), "("},
+		"SELECT a FROM t GROUP BY a WITH x":                    {KeywordCube, KeywordRollup, KeywordTotals},
+		"SELECT a FROM t OFFSET 1 ROWS FETCH LAST 1 ROWS ONLY": {KeywordFirst, "NEXT"},
+		"SELECT a FROM t OFFSET 1 ROWS FETCH NEXT 1 ONLY":      {KeywordRow, KeywordRows},
+		"SELECT a FROM t OFFSET 1 ROWS FETCH NEXT 1 ROWS":      {"ONLY", "WITH TIES"},
+		"SELECT a FROM t LIMIT 1 WITH TIES BY a":               {string(TokenEOF), ";"},
+	}
+	for sql, expected := range tests {
+		_, err := NewParser(sql).ParseStatements()
+		var parseError *ParseError
+		require.True(t, errors.As(err, &parseError), sql)
+		require.Equal(t, expected, parseError.Expected, sql)
+	}
+}
+
+func TestParser_ParseStatementsWithRecovery(t *testing.T) {
+	sql := `SELECT 1;
+SELECT a FROM t GROUP x;
+CREATE TABLE t (a UInt8, b f(1; 2)) ENGINE = Memory;
+SELECT 'unclosed ; SELECT 2`
+	parser := NewParser("USE db; " + sql + ";\nSELECT 3")
+	stmts, parseErrors := parser.ParseStatementsWithRecovery()
+	require.Len(t, stmts, 4)
+	require.Equal(t, "USE db", stmts[0].String(0))
+	require.Equal(t, "SELECT \n  1", strings.TrimSpace(stmts[1].String(0)))
+	// the quote failed to lex is skipped, so the parsing continues behind the ';'
+	require.Equal(t, "SELECT \n  2", strings.TrimSpace(stmts[2].String(0)))
+	require.Equal(t, "SELECT \n  3", strings.TrimSpace(stmts[3].String(0)))
+
+	input := "USE db; " + sql + ";\nSELECT 3"
+	require.Len(t, parseErrors, 3)
+	var spans []string
+	var indexes []int
+	for _, parseError := range parseErrors {
+		spans = append(spans, input[parseError.StatementStart:parseError.StatementEnd])
+		indexes = append(indexes, parseError.StatementIndex)
+	}
+	require.Equal(t, []string{
+		"SELECT a FROM t GROUP x",
+		"CREATE TABLE t (a UInt8, b f(1; 2)) ENGINE = Memory",
+		"SELECT 'unclosed ",
+	}, spans)
+	require.Equal(t, []int{2, 3, 4}, indexes)
+	require.Equal(t, 2, parseErrors[0].Line)
+	require.Equal(t, []string{KeywordBy}, parseErrors[0].Expected)
+
+	// without recovery the first error is returned
+	_, err := NewParser(input).ParseStatements()
+	var parseError *ParseError
+	require.True(t, errors.As(err, &parseError))
+	require.Equal(t, parseErrors[0].Error(), parseError.Error())
+}
+
+func TestParser_ParseStandalone(t *testing.T) {
+	expr, err := NewParser("a = 1 AND b IN (1, 2)").ParseExpr()
+	require.NoError(t, err)
+	require.Equal(t, "a = 1 AND b IN (1, 2)", expr.String(0))
+
+	columnType, err := NewParser("Map(String, Array(Nullable(UInt8)))").ParseColumnType()
+	require.NoError(t, err)
+	require.Equal(t, "Map(String,Array(Nullable(UInt8)))", columnType.String(0))
+
+	selectQuery, err := NewParser("SELECT a FROM t WHERE b = 1;").ParseSelectQuery()
+	require.NoError(t, err)
+	require.NotNil(t, selectQuery.Where)
+
+	tableIdentifier, err := NewParser("db.`my table`").ParseTableIdentifier()
+	require.NoError(t, err)
+	require.Equal(t, "db", tableIdentifier.Database.Name)
+	require.Equal(t, "my table", tableIdentifier.Table.Name)
+
+	invalidInputs := map[string]func(parser *Parser) error{
+		"a = 1 b": func(parser *Parser) error {
+			_, err := parser.ParseExpr()
+			return err
+		},
+		"": func(parser *Parser) error {
+			_, err := parser.ParseExpr()
+			return err
+		},
+		"String String": func(parser *Parser) error {
+			_, err := parser.ParseColumnType()
+			return err
+		},
+		"SELECT 1; SELECT 2": func(parser *Parser) error {
+			_, err := parser.ParseSelectQuery()
+			return err
+		},
+		"INSERT INTO t VALUES (1)": func(parser *Parser) error {
+			_, err := parser.ParseSelectQuery()
+			return err
+		},
+		"db.t.c": func(parser *Parser) error {
+			_, err := parser.ParseTableIdentifier()
+			return err
+		},
+	}
+	for input, parse := range invalidInputs {
+		err := parse(NewParser(input))
+		require.Error(t, err, input)
+		var parseError *ParseError
+		require.True(t, errors.As(err, &parseError), input)
+		require.Equal(t, len(input), parseError.StatementEnd)
+	}
+
+	_, err = NewParser("a = 1 b").ParseExpr()
+	var parseError *ParseError
+	require.True(t, errors.As(err, &parseError))
+	require.Equal(t, []string{string(TokenEOF)}, parseError.Expected)
+	require.Equal(t, 6, parseError.Offset)
+}
+
+// treeShape formats the expression with every operator enclosed in parentheses.
+func treeShape(expr Expr) string {
+	switch expr := expr.(type) {
+	case *BinaryExpr:
+		if expr.Operation == opTypeCast {
+			return "(" + treeShape(expr.LeftExpr) + "::" + expr.RightExpr.String(0) + ")"
+		}
+		op := string(expr.Operation)
+		if expr.HasNot {
+			op = "NOT " + op
+		}
+		if expr.HasGlobal {
+			op = "GLOBAL " + op
+		}
+		return "(" + treeShape(expr.LeftExpr) + " " + op + " " + treeShape(expr.RightExpr) + ")"
+	case *NotExpr:
+		return "(NOT " + treeShape(expr.Expr) + ")"
+	case *UnaryExpr:
+		return "(" + string(expr.Kind) + treeShape(expr.Expr) + ")"
+	case *IsNullExpr:
+		return "(" + treeShape(expr.Expr) + " IS NULL)"
+	case *IsNotNullExpr:
+		return "(" + treeShape(expr.Expr) + " IS NOT NULL)"
+	case *BetweenExpr:
+		op := " BETWEEN "
+		if expr.Not {
+			op = " NOT BETWEEN "
+		}
+		return "(" + treeShape(expr.Expr) + op + treeShape(expr.Low) + " AND " + treeShape(expr.High) + ")"
+	case *InExpr:
+		var right Expr = expr.List
+		if expr.SubQuery != nil {
+			right = expr.SubQuery
+		} else if expr.Table != nil {
+			right = expr.Table
+		}
+		op := "IN"
+		if expr.Not {
+			op = "NOT " + op
+		}
+		if expr.Global {
+			op = "GLOBAL " + op
+		}
+		return "(" + treeShape(expr.Expr) + " " + op + " " + treeShape(right) + ")"
+	case *SubQuery:
+		return "(...)"
+	case *ExistsExpr:
+		return "(EXISTS " + treeShape(expr.SubQuery) + ")"
+	case *QuantifiedComparisonExpr:
+		return "(" + treeShape(expr.Expr) + " " + string(expr.Operation) + " " + expr.Quantifier + " " + treeShape(expr.SubQuery) + ")"
+	case *TernaryExpr:
+		return "(" + treeShape(expr.Condition) + " ? " + treeShape(expr.TrueExpr) + " : " + treeShape(expr.FalseExpr) + ")"
+	case *IndexExpr:
+		return "(" + treeShape(expr.Object) + "[" + treeShape(expr.Index) + "])"
+	case *TupleElementExpr:
+		return "(" + treeShape(expr.Tuple) + "." + expr.Element.String(0) + ")"
+	case *CastExpr:
+		separator := " AS "
+		if expr.Separator == "," {
+			separator = ", "
+		}
+		return "CAST(" + treeShape(expr.Expr) + separator + expr.AsType.String(0) + ")"
+	}
+	return expr.String(0)
+}
+
+func TestParser_ExprPrecedence(t *testing.T) {
+	tests := map[string]string{
+		"a OR b AND c":                           "(a OR (b AND c))",
+		"a AND b OR c AND d":                     "((a AND b) OR (c AND d))",
+		"NOT a = b":                              "(NOT (a = b))",
+		"NOT a AND b":                            "((NOT a) AND b)",
+		"NOT NOT a":                              "(NOT (NOT a))",
+		"a AND NOT b = c":                        "(a AND (NOT (b = c)))",
+		"a = b = c":                              "((a = b) = c)",
+		"a < b != c >= d":                        "(((a < b) != c) >= d)",
+		"a || b || c":                            "((a || b) || c)",
+		"a || b = c":                             "((a || b) = c)",
+		"a + b || c":                             "((a + b) || c)",
+		"1 - 2 - 3":                              "((1 - 2) - 3)",
+		"2 * 3 + 4 / 5 % 6":                      "((2 * 3) + ((4 / 5) % 6))",
+		"a div 2 mod 3":                          "((a DIV 2) MOD 3)",
+		"a -1":                                   "(a - 1)",
+		"a-1":                                    "(a - 1)",
+		"f(x)-1":                                 "(f(x) - 1)",
+		"a * -1":                                 "(a * -1)",
+		"-a * b":                                 "((-a) * b)",
+		"-a[1]":                                  "(-(a[1]))",
+		"- -a":                                   "(-(-a))",
+		"-x::Int8":                               "(-(x::Int8))",
+		"x::Int32 + 1":                           "((x::Int32) + 1)",
+		"a IS NULL AND b IS NOT NULL":            "((a IS NULL) AND (b IS NOT NULL))",
+		"a = b IS NULL":                          "((a = b) IS NULL)",
+		"NOT a IS NULL":                          "(NOT (a IS NULL))",
+		"a IS NOT DISTINCT FROM b AND c":         "((a IS NOT DISTINCT FROM b) AND c)",
+		"a + 1 IS DISTINCT FROM b":               "((a + 1) IS DISTINCT FROM b)",
+		"a NOT IN (1, 2) OR b GLOBAL NOT IN t":   "((a NOT IN (1, 2)) OR (b GLOBAL NOT IN t))",
+		"a GLOBAL IN t AND b IN (1)":             "((a GLOBAL IN t) AND (b IN (1)))",
+		"a LIKE 'x%' AND b NOT ILIKE 'y'":        "((a LIKE 'x%') AND (b NOT ILIKE 'y'))",
+		"data.a.:Int64 + 1":                      "(data.a.:Int64 + 1)",
+		"data.^a.b = c":                          "(data.^a.b = c)",
+		"a IN (SELECT 1) OR b":                   "((a IN (...)) OR b)",
+		"a IN ((SELECT 1) UNION ALL (SELECT 2))": "(a IN (...))",
+		"a + 1 IN db.t":                          "((a + 1) IN db.t)",
+		"a IN f(x) AND b":                        "((a IN f(x)) AND b)",
+		"a > ALL (SELECT 1) AND b":               "((a > ALL (...)) AND b)",
+		"a = ANY (SELECT 1) + 1":                 "(a = ANY ((...) + 1))",
+		"a < ANY (SELECT 1) * 2 OR b":            "((a < ANY ((...) * 2)) OR b)",
+		"NOT EXISTS (SELECT 1) OR a":             "((NOT (EXISTS (...))) OR a)",
+		"a ? b : c ? d : e":                      "(a ? b : (c ? d : e))",
+		"a OR b ? c : d":                         "((a OR b) ? c : d)",
+		"a ? b OR c : d AND e":                   "(a ? (b OR c) : (d AND e))",
+		"(a + b) * c":                            "((a + b) * c)",
+		"a BETWEEN 1 AND 2 AND b":                "((a BETWEEN 1 AND 2) AND b)",
+		"a NOT BETWEEN b + 1 AND c * 2 OR d":     "((a NOT BETWEEN (b + 1) AND (c * 2)) OR d)",
+		"NOT a BETWEEN 1 AND 2":                  "(NOT (a BETWEEN 1 AND 2))",
+		"a = b BETWEEN 0 AND 1":                  "((a = b) BETWEEN 0 AND 1)",
+		"a BETWEEN 0 AND 1 IS NULL":              "((a BETWEEN 0 AND 1) IS NULL)",
+		"a IS NULL BETWEEN 0 AND 1":              "((a IS NULL) BETWEEN 0 AND 1)",
+		"a IS NOT NULL = b":                      "((a IS NOT NULL) = b)",
+		"'x' || 1 + 2":                           "('x' || (1 + 2))",
+		"a || b + c = d":                         "((a || (b + c)) = d)",
+		"a - b || c * d":                         "((a - b) || (c * d))",
+		"CAST(a + b AS String) || c":             "(CAST((a + b) AS String) || c)",
+		"CAST(a + b, 'String')":                  "CAST((a + b), 'String')",
+		"m['k'][1].2 + 1":                        "((((m['k'])[1]).2) + 1)",
+	}
+	for input, expected := range tests {
+		expr, err := NewParser(input).ParseExpr()
+		require.NoError(t, err, input)
+		require.Equal(t, expected, treeShape(expr), input)
+
+		// the formatted expression keeps the tree shape
+		formatted, err := NewParser(expr.String(0)).ParseExpr()
+		require.NoError(t, err, expr.String(0))
+		require.Equal(t, expected, treeShape(formatted), expr.String(0))
+	}
+}
+
+func TestParser_FormatAddsParenthesesByPrecedence(t *testing.T) {
+	a, b, c := &Ident{Name: "a"}, &Ident{Name: "b"}, &Ident{Name: "c"}
+	binary := func(left Expr, op TokenKind, right Expr) *BinaryExpr {
+		return &BinaryExpr{LeftExpr: left, Operation: op, RightExpr: right}
+	}
+	tests := []struct {
+		expr     Expr
+		expected string
+	}{
+		{binary(binary(a, opTypePlus, b), opTypeMul, c), "(a + b) * c"},
+		{binary(a, opTypeMinus, binary(b, opTypeMinus, c)), "a - (b - c)"},
+		{binary(binary(a, opTypeMinus, b), opTypeMinus, c), "a - b - c"},
+		{binary(a, opTypeMinus, binary(b, opTypeMul, c)), "a - b * c"},
+		{binary(a, opTypeOr, binary(b, opTypeAnd, c)), "a OR b AND c"},
+		{binary(binary(a, opTypeOr, b), opTypeAnd, c), "(a OR b) AND c"},
+		{&NotExpr{Expr: binary(a, opTypeAnd, b)}, "NOT (a AND b)"},
+		{&NotExpr{Expr: binary(a, opTypeEQ, b)}, "NOT a = b"},
+		{binary(&NotExpr{Expr: a}, opTypeEQ, b), "(NOT a) = b"},
+		{&UnaryExpr{Kind: opTypeMinus, Expr: binary(a, opTypePlus, b)}, "-(a + b)"},
+		{&UnaryExpr{Kind: opTypeMinus, Expr: &NumberLiteral{Literal: "-1"}}, "- -1"},
+		{&IsNullExpr{Expr: binary(a, opTypeEQ, b)}, "a = b IS NULL"},
+		{&IsNullExpr{Expr: &NotExpr{Expr: a}}, "(NOT a) IS NULL"},
+		{&IndexExpr{Object: binary(a, opTypeConcat, b), Index: c}, "(a || b)[c]"},
+		{&TernaryExpr{Condition: &TernaryExpr{Condition: a, TrueExpr: b, FalseExpr: c}, TrueExpr: b, FalseExpr: c}, "(a ? b : c) ? b : c"},
+		{&BetweenExpr{Expr: a, Low: binary(b, opTypeAnd, c), High: c}, "a BETWEEN (b AND c) AND c"},
+		{&BetweenExpr{Expr: binary(a, opTypeOr, b), Not: true, Low: b, High: binary(b, opTypePlus, c)}, "(a OR b) NOT BETWEEN b AND b + c"},
+		{binary(&LambdaExpr{Params: []*Ident{a}, Body: a}, opTypeEQ, b), "(a -> a) = b"},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.expected, tt.expr.String(0))
+	}
+}
+
+// setOperationShape renders the set operations with explicit parentheses and each query as its first column.
+func setOperationShape(expr Expr) string {
+	switch expr := expr.(type) {
+	case *SelectQuery:
+		if expr.SetOperation != nil {
+			return setOperationShape(expr.SetOperation)
+		}
+		return expr.SelectColumns.Items[0].String(0)
+	case *SetOperationExpr:
+		op := expr.Operator
+		if expr.Modifier != "" {
+			op += " " + expr.Modifier
+		}
+		return "(" + setOperationShape(expr.Left) + " " + op + " " + setOperationShape(expr.Right) + ")"
+	}
+	return expr.String(0)
+}
+
+func TestParser_SetOperationPrecedence(t *testing.T) {
+	tests := map[string]string{
+		"SELECT 1 UNION ALL SELECT 2 UNION ALL SELECT 3":                 "((1 UNION ALL 2) UNION ALL 3)",
+		"SELECT 1 UNION ALL SELECT 2 INTERSECT SELECT 3":                 "(1 UNION ALL (2 INTERSECT 3))",
+		"SELECT 1 INTERSECT SELECT 2 EXCEPT SELECT 3":                    "((1 INTERSECT 2) EXCEPT 3)",
+		"SELECT 1 EXCEPT SELECT 2 UNION DISTINCT SELECT 3":               "((1 EXCEPT 2) UNION DISTINCT 3)",
+		"(SELECT 1 UNION ALL SELECT 2) INTERSECT SELECT 3":               "((1 UNION ALL 2) INTERSECT 3)",
+		"SELECT 1 EXCEPT (SELECT 2 EXCEPT SELECT 3)":                     "(1 EXCEPT (2 EXCEPT 3))",
+		"SELECT 1 UNION SELECT 2":                                        "(1 UNION 2)",
+		"(SELECT 1) INTERSECT ALL ((SELECT 2) EXCEPT DISTINCT SELECT 3)": "(1 INTERSECT ALL (2 EXCEPT DISTINCT 3))",
+	}
+	for input, expected := range tests {
+		query, err := NewParser(input).ParseSelectQuery()
+		require.NoError(t, err, input)
+		require.Equal(t, expected, setOperationShape(query), input)
+
+		formatted, err := NewParser(query.String(0)).ParseSelectQuery()
+		require.NoError(t, err, query.String(0))
+		require.Equal(t, expected, setOperationShape(formatted), query.String(0))
+	}
+}
+
+func TestParser_FormatKeepsParenthesesOfSetOperands(t *testing.T) {
+	// the inputs and the numbers of parentheses kept by the formatted queries
+	tests := map[string]int{
+		"(SELECT 1) UNION ALL (SELECT 2)":                         0,
+		"(SELECT 1 LIMIT 1) UNION ALL SELECT 2":                   1,
+		"SELECT 1 UNION ALL (SELECT 2 ORDER BY 1)":                1,
+		"(WITH 1 AS x SELECT x) UNION ALL SELECT 2":               1,
+		"SELECT 1 UNION ALL (SELECT 2 SETTINGS a = 1)":            1,
+		"SELECT * FROM (SELECT 1 LIMIT 1)":                        1,
+		"SELECT * FROM ((SELECT 1 LIMIT 1) UNION ALL (SELECT 2))": 2,
+	}
+	for input, parentheses := range tests {
+		query, err := NewParser(input).ParseSelectQuery()
+		require.NoError(t, err, input)
+		formatted := query.String(0)
+		require.Equal(t, parentheses, strings.Count(formatted, "("), formatted)
+
+		reparsed, err := NewParser(formatted).ParseSelectQuery()
+		require.NoError(t, err, formatted)
+		require.Equal(t, formatted, reparsed.String(0))
+	}
+}
@@ -0,0 +1,15 @@
+-- create the events table
+/* owned by
+   the analytics team */
+CREATE TABLE events ON CLUSTER 'default_cluster'
+(
+    id UInt64, -- primary id
+    /* the event name */
+    name String,
+    timestamp DateTime -- event time
+) ENGINE = MergeTree
+-- sort by id
+ORDER BY id; -- done
+
+-- drop the legacy table
+DROP TABLE IF EXISTS events_legacy;
@@ -0,0 +1,5 @@
+
+SELECT 
+  [1, /* two */ 2, 3 -- three
+] AS arr,
+  [[1 /* inner */], [2]] AS nested;
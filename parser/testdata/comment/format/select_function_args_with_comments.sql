@@ -0,0 +1,8 @@
+
+SELECT 
+  f(a, /* first */ b), -- the call
+  g(x, -- the x
+y) AS z,
+  count(DISTINCT id /* the id */)
+FROM
+  t;
@@ -0,0 +1,10 @@
+
+SELECT 
+  a
+FROM
+  t
+WHERE
+  a IN (1, /* one */ 2, -- two
+3) AND b NOT IN ('x', /* y */ 'y') -- end of query
+-- end of file
+;
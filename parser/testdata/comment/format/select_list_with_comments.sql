@@ -0,0 +1,19 @@
+
+SELECT 
+  a, -- col a
+  b /* col b */
+FROM
+  t;
+
+SELECT 
+  -- the user
+  user_id,
+  sum(amount) AS total, /* the total */
+  count() -- the count
+FROM
+  orders
+GROUP BY user_id;
+
+SELECT 
+  1 -- one
+;
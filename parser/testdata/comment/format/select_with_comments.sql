@@ -0,0 +1,14 @@
+
+-- top users
+SELECT 
+  user_id,
+  count() AS cnt
+FROM
+  events -- the events table
+-- only recent events
+WHERE
+  timestamp > now() - INTERVAL 1 DAY AND name = 'click' /* inline */
+GROUP BY user_id -- per user
+ORDER BY cnt DESC
+LIMIT 10 -- end of file
+;
@@ -0,0 +1,6 @@
+SELECT f(a /* first */, b), -- the call
+    g(x, -- the x
+      y
+    ) AS z,
+    count(DISTINCT id /* the id */)
+FROM t;
@@ -0,0 +1,9 @@
+-- top users
+SELECT user_id, count() AS cnt
+FROM events -- the events table
+    -- only recent events
+WHERE timestamp > now() - INTERVAL 1 DAY /* inline */ AND name = 'click'
+GROUP BY user_id -- per user
+ORDER BY cnt DESC
+LIMIT 10;
+-- end of file
@@ -0,0 +1,2 @@
+ALTER TABLE events ADD PROJECTION p_by_user
+(SELECT user_id, ts GROUP BY user_id, ts ORDER BY user_id DESC NULLS LAST, ts COLLATE 'en');
@@ -0,0 +1 @@
+CREATE FUNCTION extract_key AS (s) -> JSONExtractString(s, $key$user's key$key$);
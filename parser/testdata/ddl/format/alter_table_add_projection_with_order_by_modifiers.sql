@@ -0,0 +1,8 @@
+-- Origin SQL:
+ALTER TABLE events ADD PROJECTION p_by_user
+(SELECT user_id, ts GROUP BY user_id, ts ORDER BY user_id DESC NULLS LAST, ts COLLATE 'en');
+
+
+-- Format SQL:
+ALTER TABLE events
+ADD PROJECTION p_by_user (SELECT user_id, ts GROUP BY user_id, ts ORDER BY user_id DESC NULLS LAST, ts COLLATE 'en');
@@ -0,0 +1,6 @@
+-- Origin SQL:
+CREATE FUNCTION extract_key AS (s) -> JSONExtractString(s, $key$user's key$key$);
+
+
+-- Format SQL:
+CREATE FUNCTION extract_key AS (s) -> JSONExtractString(s, $key$user's key$key$);
@@ -0,0 +1,24 @@
+-- Origin SQL:
+CREATE TABLE t
+(
+    id UInt64,
+    attrs Map(String, String) DEFAULT {'source': 'unknown'},
+    point Tuple(Float64, Float64) DEFAULT (0, 0),
+    single Tuple(UInt8) DEFAULT (1,)
+)
+ENGINE = MergeTree
+ORDER BY id
+SETTINGS index_granularity = 8192;
+
+
+-- Format SQL:
+CREATE TABLE t
+(
+  id UInt64,
+  attrs Map(String,String) DEFAULT {'source': 'unknown'},
+  point Tuple(Float64,Float64) DEFAULT (0, 0),
+  single Tuple(UInt8) DEFAULT (1,)
+)
+ENGINE = MergeTree
+SETTINGS index_granularity=8192
+ORDER BY id;
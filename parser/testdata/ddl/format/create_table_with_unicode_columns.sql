@@ -0,0 +1,17 @@
+-- Origin SQL:
+CREATE TABLE 用户表 (
+    编号 UInt64,
+    名字 String COMMENT '用户名',
+    Größe Float64 DEFAULT 0
+) ENGINE = MergeTree ORDER BY 编号;
+
+
+-- Format SQL:
+CREATE TABLE 用户表
+(
+  编号 UInt64,
+  名字 String COMMENT '用户名',
+  Größe Float64 DEFAULT 0
+)
+ENGINE = MergeTree
+ORDER BY 编号;
@@ -0,0 +1,9 @@
+-- Origin SQL:
+INSERT INTO {tbl:Identifier} SELECT {id:UInt64}, {name:String};
+
+
+-- Format SQL:
+INSERT INTO TABLE {tbl:Identifier}
+SELECT 
+  {id:UInt64},
+  {name:String};
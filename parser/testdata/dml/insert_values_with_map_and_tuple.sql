@@ -0,0 +1,4 @@
+INSERT INTO t (id, attrs, point, tags) VALUES
+    (1, {'color': 'red', 'size': 'L'}, (1.5, 2.5), ['a', 'b']),
+    (2, {}, (0, 0), []),
+    (3, map('k', 'v'), (1,), [(1, 'x')])
@@ -0,0 +1 @@
+INSERT INTO {tbl:Identifier} SELECT {id:UInt64}, {name:String};
@@ -8,5 +8,5 @@ SELECT
   COUNT(b)
 FROM
   group_by_all
-GROUP BY CUBE((a)) WITH CUBE WITH TOTALS
+GROUP BY CUBE(a) WITH CUBE WITH TOTALS
 ORDER BY a;
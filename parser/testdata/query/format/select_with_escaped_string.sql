@@ -0,0 +1,18 @@
+-- Origin SQL:
+SELECT 'it\'s', 'a''b', '\n\t', '\x41\x42', 'C:\\temp', match(path, '^/api/\d+$'), '' AS empty FROM logs WHERE msg LIKE '100\%';
+
+
+-- Format SQL:
+
+SELECT 
+  'it\'s',
+  'a\'b',
+  '\n\t',
+  'AB',
+  'C:\\temp',
+  match(path, '^/api/\d+$'),
+  '' AS empty
+FROM
+  logs
+WHERE
+  msg LIKE '100\%';
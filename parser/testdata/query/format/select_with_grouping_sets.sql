@@ -0,0 +1,62 @@
+-- Origin SQL:
+SELECT region, product, sum(amount), grouping(region, product) AS level
+FROM sales
+GROUP BY ROLLUP(region, product)
+HAVING grouping(region, product) < 3
+ORDER BY level;
+
+SELECT region, product, sum(amount) FROM sales GROUP BY CUBE(region, product) WITH TOTALS;
+
+SELECT region, product, sum(amount), grouping(region)
+FROM sales
+GROUP BY GROUPING SETS ((region, product), (region), product, ());
+
+SELECT region, product, sum(amount) FROM sales GROUP BY ALL WITH TOTALS;
+
+SELECT grouping, sets FROM grouping_sets GROUP BY grouping, sets;
+
+
+-- Format SQL:
+
+SELECT 
+  region,
+  product,
+  sum(amount),
+  grouping(region, product) AS level
+FROM
+  sales
+GROUP BY ROLLUP(region, product)
+HAVING grouping(region, product) < 3
+ORDER BY level;
+
+SELECT 
+  region,
+  product,
+  sum(amount)
+FROM
+  sales
+GROUP BY CUBE(region, product) WITH TOTALS;
+
+SELECT 
+  region,
+  product,
+  sum(amount),
+  grouping(region)
+FROM
+  sales
+GROUP BY GROUPING SETS ((region, product), (region), (product), ());
+
+SELECT 
+  region,
+  product,
+  sum(amount)
+FROM
+  sales
+GROUP BY ALL WITH TOTALS;
+
+SELECT 
+  grouping,
+  sets
+FROM
+  grouping_sets
+GROUP BY grouping, sets;
@@ -0,0 +1,13 @@
+-- Origin SQL:
+SELECT $$it's a \n raw string$$, $json${"key": "value"}$json$, match(path, $re$^/api/\d+$$re$) AS matched, $$$$ AS empty FROM logs;
+
+
+-- Format SQL:
+
+SELECT 
+  $$it's a \n raw string$$,
+  $json${"key": "value"}$json$,
+  match(path, $re$^/api/\d+$$re$) AS matched,
+  $$$$ AS empty
+FROM
+  logs;
@@ -0,0 +1,31 @@
+-- Origin SQL:
+SELECT
+    data.user.id,
+    data.user.id.:Int64 AS user_id,
+    data.user.profile.name.:LowCardinality(String),
+    data.^user,
+    events.data.^user.profile,
+    data.order.items.:Array(JSON),
+    variant.String,
+    dynamic.:Nullable(Int64),
+    t.tags.1
+FROM events
+WHERE data.user.country.:String = 'NZ';
+
+
+-- Format SQL:
+
+SELECT 
+  data.user.id,
+  data.user.id.:Int64 AS user_id,
+  data.user.profile.name.:LowCardinality(String),
+  data.^user,
+  events.data.^user.profile,
+  data.order.items.:Array(JSON),
+  variant.String,
+  dynamic.:Nullable(Int64),
+  t.tags.1
+FROM
+  events
+WHERE
+  data.user.country.:String = 'NZ';
@@ -0,0 +1,21 @@
+-- Origin SQL:
+SELECT
+    arrayMap(x -> x * 2, arr) AS doubled,
+    arrayFilter((k, v) -> v > 0 AND k != '', keys, vals),
+    arrayMap((x) -> arrayExists(y -> y = x, other), arr),
+    arraySort(x -> -x, arr)
+FROM t
+WHERE arrayAll(x -> x IS NOT NULL, arr);
+
+
+-- Format SQL:
+
+SELECT 
+  arrayMap(x -> x * 2, arr) AS doubled,
+  arrayFilter((k, v) -> v > 0 AND k != '', keys, vals),
+  arrayMap((x) -> arrayExists(y -> y = x, other), arr),
+  arraySort(x -> -x, arr)
+FROM
+  t
+WHERE
+  arrayAll(x -> x IS NOT NULL, arr);
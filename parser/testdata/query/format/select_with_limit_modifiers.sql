@@ -0,0 +1,107 @@
+-- Origin SQL:
+SELECT id, score FROM scores ORDER BY score DESC LIMIT 3 WITH TIES;
+
+SELECT id, score FROM scores ORDER BY score DESC LIMIT 10, 3 WITH TIES;
+
+SELECT id, score FROM scores ORDER BY score DESC OFFSET 10 ROWS FETCH FIRST 5 ROWS ONLY;
+
+SELECT id, score FROM scores ORDER BY score DESC OFFSET 1 ROW FETCH NEXT 1 ROW WITH TIES;
+
+SELECT id, score FROM scores ORDER BY id OFFSET 5;
+
+SELECT id, score FROM scores ORDER BY id LIMIT -5 OFFSET -2;
+
+SELECT id, score FROM scores ORDER BY id LIMIT 0.1;
+
+SELECT id, domain, score FROM scores ORDER BY score DESC LIMIT 2 OFFSET 1 BY domain LIMIT 10;
+
+SELECT id, domain, score FROM scores ORDER BY score DESC LIMIT 1 BY domain OFFSET 3 SETTINGS max_threads = 1;
+
+SELECT id FROM scores ORDER BY id OFFSET 5 ROWS;
+
+
+-- Format SQL:
+
+SELECT 
+  id,
+  score
+FROM
+  scores
+ORDER BY score DESC
+LIMIT 3 WITH TIES;
+
+SELECT 
+  id,
+  score
+FROM
+  scores
+ORDER BY score DESC
+LIMIT 3 OFFSET 10 WITH TIES;
+
+SELECT 
+  id,
+  score
+FROM
+  scores
+ORDER BY score DESC
+OFFSET 10 ROWS FETCH FIRST 5 ROWS ONLY;
+
+SELECT 
+  id,
+  score
+FROM
+  scores
+ORDER BY score DESC
+OFFSET 1 ROW FETCH NEXT 1 ROW WITH TIES;
+
+SELECT 
+  id,
+  score
+FROM
+  scores
+ORDER BY id
+OFFSET 5;
+
+SELECT 
+  id,
+  score
+FROM
+  scores
+ORDER BY id
+LIMIT -5 OFFSET -2;
+
+SELECT 
+  id,
+  score
+FROM
+  scores
+ORDER BY id
+LIMIT 0.1;
+
+SELECT 
+  id,
+  domain,
+  score
+FROM
+  scores
+ORDER BY score DESC
+LIMIT 2 OFFSET 1 BY domain
+LIMIT 10;
+
+SELECT 
+  id,
+  domain,
+  score
+FROM
+  scores
+ORDER BY score DESC
+LIMIT 1 BY domain
+OFFSET 3
+SETTINGS max_threads=1;
+
+SELECT 
+  id
+FROM
+  scores
+ORDER BY id
+OFFSET 5 ROWS;
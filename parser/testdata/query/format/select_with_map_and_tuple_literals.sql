@@ -0,0 +1,33 @@
+-- Origin SQL:
+SELECT
+    {'a': 1, 'b': 2} AS m,
+    {} AS empty_map,
+    {'k': [1, 2], 'v': {'nested': (1, 'x')}}['k'][1],
+    (1, 'x') AS t,
+    (1,) AS single,
+    () AS empty_tuple,
+    (a + b) * c,
+    ((1, 2), (3, 4)).2.1,
+    {id:UInt64} AS param
+FROM t
+WHERE (a, b) IN ((1, 2), (3, 4))
+SETTINGS additional_table_filters = {'t': 'x > 1'}, max_threads = 8, param_list = [1, 2], param_tuple = (1, 'x');
+
+
+-- Format SQL:
+
+SELECT 
+  {'a': 1, 'b': 2} AS m,
+  {} AS empty_map,
+  {'k': [1, 2], 'v': {'nested': (1, 'x')}}['k'][1],
+  (1, 'x') AS t,
+  (1,) AS single,
+  () AS empty_tuple,
+  (a + b) * c,
+  ((1, 2), (3, 4)).2.1,
+  {id:UInt64} AS param
+FROM
+  t
+WHERE
+  (a, b) IN ((1, 2), (3, 4))
+SETTINGS additional_table_filters={'t': 'x > 1'}, max_threads=8, param_list=[1, 2], param_tuple=(1, 'x');
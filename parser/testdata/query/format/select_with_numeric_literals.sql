@@ -0,0 +1,26 @@
+-- Origin SQL:
+SELECT 0b1010, 0B11, 1_000_000, 0X1F, 0xdead_beef, 0x1.8p3, 0x1p-2, 1.5e3, -0x10, inf, -inf, nan, -nan, Infinity, isNaN(nan) FROM numbers(10) WHERE number < 1_000 AND 0.5 > -1e-3;
+
+
+-- Format SQL:
+
+SELECT 
+  0b1010,
+  0B11,
+  1_000_000,
+  0X1F,
+  0xdead_beef,
+  0x1.8p3,
+  0x1p-2,
+  1.5e3,
+  -0x10,
+  inf,
+  -inf,
+  nan,
+  -nan,
+  Infinity,
+  isNaN(nan)
+FROM
+  numbers(10)
+WHERE
+  number < 1_000 AND 0.5 > -1e-3;
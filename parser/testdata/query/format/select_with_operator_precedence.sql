@@ -0,0 +1,31 @@
+-- Origin SQL:
+SELECT
+    a || '-' || b AS joined,
+    x -1,
+    -price * quantity,
+    NOT a = b AND c,
+    a IS NOT DISTINCT FROM b,
+    a IS DISTINCT FROM b OR c IS NULL,
+    a = b = c,
+    n div 2 mod 3,
+    flag ? 1 : other ? 2 : 3
+FROM t
+WHERE id GLOBAL NOT IN (1, 2, 3) AND name NOT LIKE 'x%';
+
+
+-- Format SQL:
+
+SELECT 
+  a || '-' || b AS joined,
+  x - 1,
+  -price * quantity,
+  NOT a = b AND c,
+  a IS NOT DISTINCT FROM b,
+  a IS DISTINCT FROM b OR c IS NULL,
+  a = b = c,
+  n DIV 2 MOD 3,
+  flag ? 1 : other ? 2 : 3
+FROM
+  t
+WHERE
+  id GLOBAL NOT IN (1, 2, 3) AND name NOT LIKE 'x%';
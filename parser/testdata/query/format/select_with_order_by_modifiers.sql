@@ -0,0 +1,32 @@
+-- Origin SQL:
+SELECT
+    t,
+    v,
+    row_number() OVER (PARTITION BY k ORDER BY v DESC NULLS LAST) AS rn
+FROM metrics
+ORDER BY
+    name ASC NULLS FIRST COLLATE 'de',
+    t WITH FILL FROM toStartOfHour(now() - INTERVAL 1 DAY) TO toStartOfHour(now()) STEP INTERVAL 1 HOUR STALENESS INTERVAL 2 HOUR,
+    step DESC WITH FILL STEP -1
+INTERPOLATE (v AS v + 1, step);
+
+SELECT n FROM numbers(10) ORDER BY n WITH FILL INTERPOLATE;
+
+
+-- Format SQL:
+
+SELECT 
+  t,
+  v,
+  row_number() OVER (
+  PARTITION BY k
+  ORDER BY v DESC NULLS LAST) AS rn
+FROM
+  metrics
+ORDER BY name ASC NULLS FIRST COLLATE 'de', t WITH FILL FROM toStartOfHour(now() - INTERVAL 1 DAY) TO toStartOfHour(now()) STEP INTERVAL 1 HOUR STALENESS INTERVAL 2 HOUR, step DESC WITH FILL STEP -1 INTERPOLATE (v AS v + 1, step);
+
+SELECT 
+  n
+FROM
+  numbers(10)
+ORDER BY n WITH FILL INTERPOLATE;
@@ -0,0 +1,30 @@
+-- Origin SQL:
+SELECT
+    quantiles(0.5, 0.9)(latency) AS q,
+    quantileIf(0.99)(latency, status = 200) AS p99,
+    sumIf(bytes, status >= 500),
+    uniqArrayIf(tags, length(tags) > 0),
+    countState(),
+    argMaxMerge(last_state),
+    uniqExact(DISTINCT user_id),
+    sequenceMatch('(?1)(?2)')(ts, event = 'view', event = 'buy'),
+    groupArrayResample(30, 75, 30)(name, age)
+FROM events
+GROUP BY day;
+
+
+-- Format SQL:
+
+SELECT 
+  quantiles(0.5, 0.9)(latency) AS q,
+  quantileIf(0.99)(latency, status = 200) AS p99,
+  sumIf(bytes, status >= 500),
+  uniqArrayIf(tags, length(tags) > 0),
+  countState(),
+  argMaxMerge(last_state),
+  uniqExact(DISTINCT user_id),
+  sequenceMatch('(?1)(?2)')(ts, event = 'view', event = 'buy'),
+  groupArrayResample(30, 75, 30)(name, age)
+FROM
+  events
+GROUP BY day;
@@ -0,0 +1,41 @@
+-- Origin SQL:
+SELECT user_id, event, ts
+FROM events
+WHERE ts > '2024-01-01'
+QUALIFY row_number() OVER (PARTITION BY user_id ORDER BY ts DESC) = 1;
+
+SELECT domain, count() AS hits, rank() OVER (ORDER BY count() DESC) AS r
+FROM visits
+GROUP BY domain
+HAVING hits > 10
+QUALIFY r <= 3
+ORDER BY r
+LIMIT 3;
+
+
+-- Format SQL:
+
+SELECT 
+  user_id,
+  event,
+  ts
+FROM
+  events
+WHERE
+  ts > '2024-01-01'
+QUALIFY row_number() OVER (
+  PARTITION BY user_id
+  ORDER BY ts DESC) = 1;
+
+SELECT 
+  domain,
+  count() AS hits,
+  rank() OVER (
+  ORDER BY count() DESC) AS r
+FROM
+  visits
+GROUP BY domain
+HAVING hits > 10
+QUALIFY r <= 3
+ORDER BY r
+LIMIT 3;
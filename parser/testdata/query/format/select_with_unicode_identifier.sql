@@ -0,0 +1,17 @@
+-- Origin SQL:
+SELECT 名字, Größe AS größe_gesamt, "Straße", `用户 名`, count() AS 数量 FROM 数据库.用户表 WHERE 名字 = '张三' AND café > 1 GROUP BY 名字, Größe, "Straße", `用户 名`;
+
+
+-- Format SQL:
+
+SELECT 
+  名字,
+  Größe AS größe_gesamt,
+  "Straße",
+  `用户 名`,
+  count() AS 数量
+FROM
+  数据库.用户表
+WHERE
+  名字 = '张三' AND café > 1
+GROUP BY 名字, Größe, "Straße", `用户 名`;
@@ -0,0 +1,5 @@
+SELECT count()
+FROM events
+WHERE ts BETWEEN now() - INTERVAL 1 DAY AND now()
+  AND amount NOT BETWEEN 10 AND 100
+  AND (score BETWEEN 0.5 AND 1 OR score IS NULL);
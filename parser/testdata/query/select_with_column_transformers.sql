@@ -0,0 +1,10 @@
+SELECT
+    * EXCEPT (secret) REPLACE (round(x, 2) AS x),
+    COLUMNS('^metric_') APPLY(sum),
+    COLUMNS(a, b) APPLY toString,
+    t.* EXCEPT col,
+    s.* EXCEPT STRICT ('^tmp_') REPLACE STRICT (y * 2 AS y, z + 1 AS z) APPLY(x -> x + 1),
+    * REPLACE (x + 1) AS x APPLY(quantile(0.9))
+FROM t, s;
+
+SELECT db.t.* EXCEPT (id), db.t.* APPLY toString FROM db.t;
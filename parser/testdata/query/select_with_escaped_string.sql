@@ -0,0 +1 @@
+SELECT 'it\'s', 'a''b', '\n\t', '\x41\x42', 'C:\\temp', match(path, '^/api/\d+$'), '' AS empty FROM logs WHERE msg LIKE '100\%';
@@ -0,0 +1,9 @@
+SELECT
+    count() FILTER (WHERE status >= 500) AS errors,
+    sumIf(bytes, ok) FILTER (WHERE region = 'eu' AND bytes > 0),
+    any(x) RESPECT NULLS,
+    first_value(x) IGNORE NULLS OVER w,
+    last_value(x) respect nulls OVER (PARTITION BY id ORDER BY ts),
+    uniq(user_id) FILTER (WHERE user_id IN (SELECT id FROM vip)) AS vips
+FROM events
+WINDOW w AS (PARTITION BY id ORDER BY ts);
@@ -0,0 +1 @@
+SELECT $$it's a \n raw string$$, $json${"key": "value"}$json$, match(path, $re$^/api/\d+$$re$) AS matched, $$$$ AS empty FROM logs;
@@ -0,0 +1,13 @@
+SELECT
+    arr[1] + 1,
+    m['k'][2],
+    -arr[length(arr)],
+    t.1,
+    t.1.2,
+    tuple(1, 'a').2,
+    kv['a'].name,
+    db.tbl.col[1],
+    [10, 20][1],
+    CAST(arr[1] AS String)
+FROM t
+WHERE m['k'][2] > 0.5 AND t.2 = .5;
@@ -0,0 +1 @@
+SELECT {col:Identifier}, count() AS cnt FROM {db:Identifier}.{tbl:Identifier} WHERE id = {id:UInt64} AND name IN {names:Array(String)} AND ts > {start:DateTime('UTC')} GROUP BY {col:Identifier} LIMIT {limit:UInt32};
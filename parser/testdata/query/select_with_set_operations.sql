@@ -0,0 +1,16 @@
+SELECT a FROM t1
+UNION ALL
+SELECT a FROM t2 INTERSECT SELECT a FROM t3
+EXCEPT DISTINCT
+SELECT a FROM t4;
+
+(SELECT a FROM t1 UNION ALL SELECT a FROM t2) EXCEPT SELECT a FROM t3;
+
+SELECT a FROM t1 EXCEPT (SELECT a FROM t2 UNION DISTINCT SELECT a FROM t3);
+
+SELECT a FROM t1 UNION SELECT a FROM t2 INTERSECT ALL (SELECT a FROM t3 ORDER BY a LIMIT 1);
+
+SELECT count() FROM (SELECT a FROM t1 INTERSECT DISTINCT SELECT a FROM t2) WHERE a IN (SELECT a FROM t3 UNION ALL SELECT a FROM t4);
+
+SELECT x IN ((SELECT 1) UNION ALL (SELECT 2)), ((SELECT 1) + 1) AS y
+WHERE EXISTS ((SELECT 1) EXCEPT (SELECT 2));
@@ -0,0 +1,12 @@
+SELECT
+    id,
+    (SELECT max(ts) FROM events) AS last_ts,
+    id IN (1, 2, 3) AS in_list
+FROM accounts
+WHERE id IN (SELECT account_id FROM grants WHERE user_id = 42)
+  AND tenant_id GLOBAL NOT IN (SELECT id FROM blocked_tenants)
+  AND region IN db.allowed_regions
+  AND EXISTS (SELECT 1 FROM owners WHERE owners.account_id = accounts.id)
+  AND NOT EXISTS (WITH 1 AS x SELECT x)
+  AND balance > ALL (SELECT min_balance FROM tiers)
+  AND tier = ANY (SELECT tier FROM active_tiers);
@@ -22,6 +22,18 @@ const (
 	opTypeDiv   TokenKind = "/"
 	opTypeMod   TokenKind = "%"
 
+	opTypeIntDiv      TokenKind = "DIV"
+	opTypeModulo      TokenKind = "MOD"
+	opTypeConcat      TokenKind = "||"
+	opTypeLike        TokenKind = "LIKE"
+	opTypeILike       TokenKind = "ILIKE"
+	opTypeRegexp      TokenKind = "REGEXP"
+	opTypeIn          TokenKind = "IN"
+	opTypeLessGreater TokenKind = "<>"
+
+	opTypeIsDistinctFrom    TokenKind = "IS DISTINCT FROM"
+	opTypeIsNotDistinctFrom TokenKind = "IS NOT DISTINCT FROM"
+
 	opTypeArrow TokenKind = "->"
 	opTypeCast  TokenKind = "::"
 
@@ -29,3 +41,59 @@ const (
 	opTypeAnd TokenKind = "AND"
 	opTypeOr  TokenKind = "OR"
 )
+
+// The precedences of operators from the lowest to the highest, which follow the operator priorities of ClickHouse.
+const (
+	precedenceLowest = iota
+	precedenceLambda
+	precedenceTernary
+	precedenceOr
+	precedenceAnd
+	precedenceNot
+	precedenceIs
+	precedenceBetween
+	precedenceCompare
+	precedenceConcat
+	precedenceAddSub
+	precedenceMulDiv
+	precedenceUnary
+	precedencePostfix
+	precedenceHighest
+)
+
+// setOperationPrecedences are the precedences of the set operations between queries
+var setOperationPrecedences = map[string]int{
+	KeywordUnion:     1,
+	KeywordExcept:    1,
+	KeywordIntersect: 2,
+}
+
+var comparisonOperators = NewSet(string(opTypeEQ), string(opTypeDoubleEQ), string(opTypeNE), string(opTypeLessGreater),
+	string(opTypeLT), string(opTypeLE), string(opTypeGT), string(opTypeGE))
+
+var binaryOperatorPrecedences = map[TokenKind]int{
+	opTypeOr:                precedenceOr,
+	opTypeAnd:               precedenceAnd,
+	opTypeEQ:                precedenceCompare,
+	opTypeDoubleEQ:          precedenceCompare,
+	opTypeNE:                precedenceCompare,
+	opTypeLessGreater:       precedenceCompare,
+	opTypeLT:                precedenceCompare,
+	opTypeLE:                precedenceCompare,
+	opTypeGT:                precedenceCompare,
+	opTypeGE:                precedenceCompare,
+	opTypeLike:              precedenceCompare,
+	opTypeILike:             precedenceCompare,
+	opTypeRegexp:            precedenceCompare,
+	opTypeIn:                precedenceCompare,
+	opTypeIsDistinctFrom:    precedenceCompare,
+	opTypeIsNotDistinctFrom: precedenceCompare,
+	opTypeConcat:            precedenceConcat,
+	opTypePlus:              precedenceAddSub,
+	opTypeMinus:             precedenceAddSub,
+	opTypeMul:               precedenceMulDiv,
+	opTypeDiv:               precedenceMulDiv,
+	opTypeMod:               precedenceMulDiv,
+	opTypeIntDiv:            precedenceMulDiv,
+	opTypeModulo:            precedenceMulDiv,
+}
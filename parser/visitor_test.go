@@ -129,3 +129,200 @@ func TestVisitor_NestRewrite(t *testing.T) {
 	require.NotSame(t, sql, newSql)
 	require.Less(t, strings.Index(newSql, "table1"), strings.Index(newSql, "table2"))
 }
+
+func TestVisitor_QueryParameters(t *testing.T) {
+	sql := `SELECT {col:Identifier} FROM {tbl:Identifier} WHERE id = {id:UInt64} AND name IN {names:Array(String)} GROUP BY {col:Identifier}`
+	parser := NewParser(sql)
+	stmts, err := parser.ParseStatements()
+	require.NoError(t, err)
+	require.Equal(t, 1, len(stmts))
+
+	params := QueryParameters(stmts[0])
+	var got []string
+	for _, param := range params {
+		got = append(got, param.String(0))
+	}
+	require.Equal(t, []string{
+		"{col:Identifier}",
+		"{tbl:Identifier}",
+		"{id:UInt64}",
+		"{names:Array(String)}",
+	}, got)
+}
+
+type renameVisitor struct {
+	DefaultASTVisitor
+	names map[string]string
+}
+
+func (v *renameVisitor) VisitIdent(expr *Ident) error {
+	if name, ok := v.names[expr.Name]; ok {
+		expr.Name = name
+	}
+	return nil
+}
+
+func TestVisitor_RenameQuotesIdent(t *testing.T) {
+	visitor := renameVisitor{names: map[string]string{
+		"a":     "名字",
+		"b":     "Größe",
+		"c":     "order total",
+		"t":     "用户 表",
+		"alias": "1st",
+	}}
+
+	parser := NewParser(`SELECT a, b, c AS alias FROM t`)
+	stmts, err := parser.ParseStatements()
+	require.NoError(t, err)
+	require.Equal(t, 1, len(stmts))
+
+	err = stmts[0].Accept(&visitor)
+	require.NoError(t, err)
+	newSql := stmts[0].String(0)
+	require.Contains(t, newSql, "名字,")
+	require.Contains(t, newSql, "Größe,")
+	require.Contains(t, newSql, "`order total` AS `1st`")
+	require.Contains(t, newSql, "`用户 表`")
+}
+
+func TestVisitor_LambdaParamRefs(t *testing.T) {
+	sql := `SELECT arrayMap(x -> arrayFilter(x -> x > y, x.values), arr) AS x, x FROM t WHERE arrayExists((k, v) -> t.k = k, keys, vals)`
+	parser := NewParser(sql)
+	stmts, err := parser.ParseStatements()
+	require.NoError(t, err)
+	require.Equal(t, 1, len(stmts))
+
+	refs := LambdaParamRefs(stmts[0])
+	var got []string
+	for ident, lambda := range refs {
+		got = append(got, fmt.Sprintf("%s@%d in %s", ident.Name, ident.Pos(), lambda.String(0)))
+	}
+	require.ElementsMatch(t, []string{
+		"x@16 in x -> arrayFilter(x -> x > y, x.values)",
+		"x@33 in x -> x > y",
+		"x@38 in x -> x > y",
+		"x@45 in x -> arrayFilter(x -> x > y, x.values)",
+		"k@95 in (k, v) -> t.k = k",
+		"v@98 in (k, v) -> t.k = k",
+		"k@110 in (k, v) -> t.k = k",
+	}, got)
+}
+
+func TestVisitor_LambdaParamRefsSkipQueryParameters(t *testing.T) {
+	expr, err := NewParser("arrayMap(x -> {x:String} || x, arr)").ParseExpr()
+	require.NoError(t, err)
+
+	refs := LambdaParamRefs(expr)
+	var got []string
+	for ident := range refs {
+		got = append(got, fmt.Sprintf("%s@%d", ident.Name, ident.Pos()))
+	}
+	require.ElementsMatch(t, []string{"x@9", "x@28"}, got)
+}
+
+type subQueryCollector struct {
+	DefaultASTVisitor
+	tables []string
+}
+
+func (v *subQueryCollector) VisitSubQuery(expr *SubQuery) error {
+	v.tables = append(v.tables, expr.Select.From.Expr.String(0))
+	return nil
+}
+
+func (v *subQueryCollector) VisitInExpr(expr *InExpr) error {
+	if expr.Table != nil {
+		v.tables = append(v.tables, expr.Table.String(0))
+	}
+	return nil
+}
+
+func TestVisitor_SubQueryPredicates(t *testing.T) {
+	sql := `SELECT (SELECT max(ts) FROM e) FROM t
+WHERE a GLOBAL NOT IN (SELECT a FROM s) AND b IN db.r AND EXISTS (SELECT 1 FROM o) AND c > ALL (SELECT c FROM m)`
+	parser := NewParser(sql)
+	stmts, err := parser.ParseStatements()
+	require.NoError(t, err)
+	require.Equal(t, 1, len(stmts))
+
+	visitor := subQueryCollector{}
+	err = stmts[0].Accept(&visitor)
+	require.NoError(t, err)
+	require.Equal(t, []string{"e", "s", "db.r", "o", "m"}, visitor.tables)
+}
+
+func TestVisitor_ColumnTransformers(t *testing.T) {
+	sql := `SELECT t.* EXCEPT (a, b) REPLACE (c + 1 AS c), COLUMNS('^m_') APPLY(sum) FROM t`
+	parser := NewParser(sql)
+	stmts, err := parser.ParseStatements()
+	require.NoError(t, err)
+	require.Equal(t, 1, len(stmts))
+
+	var got []string
+	visitor := DefaultASTVisitor{Visit: func(expr Expr) error {
+		switch expr := expr.(type) {
+		case *ColumnsMatcher:
+			got = append(got, "matcher "+expr.Pattern.Literal)
+		case *ExceptTransformer:
+			got = append(got, fmt.Sprintf("except %d columns", len(expr.Columns)))
+		case *ReplaceTransformer:
+			got = append(got, "replace "+expr.Replaces[0].Alias.String(0))
+		case *ApplyTransformer:
+			got = append(got, "apply "+expr.Func.String(0))
+		case *ColumnTransformerExpr:
+			got = append(got, fmt.Sprintf("%s with %d transformers", expr.Columns.String(0), len(expr.Transformers)))
+		}
+		return nil
+	}}
+	err = stmts[0].Accept(&visitor)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"except 2 columns",
+		"replace c",
+		"t.* with 2 transformers",
+		"matcher ^m_",
+		"apply sum",
+		"COLUMNS('^m_') with 1 transformers",
+	}, got)
+}
+
+func TestVisitor_FunctionFilter(t *testing.T) {
+	parser := NewParser(`SELECT countIf(a) FILTER (WHERE b IN (SELECT c FROM d)) FROM t`)
+	stmts, err := parser.ParseStatements()
+	require.NoError(t, err)
+	require.Equal(t, 1, len(stmts))
+
+	var got []string
+	visitor := DefaultASTVisitor{Visit: func(expr Expr) error {
+		switch expr := expr.(type) {
+		case *FilterExpr:
+			got = append(got, "FILTER "+expr.Expr.(*InExpr).Expr.String(0))
+		case *TableIdentifier:
+			got = append(got, expr.String(0))
+		}
+		return nil
+	}}
+	err = stmts[0].Accept(&visitor)
+	require.NoError(t, err)
+	require.Equal(t, []string{"d", "FILTER b", "t"}, got)
+}
+
+func TestVisitor_Qualify(t *testing.T) {
+	parser := NewParser(`SELECT u FROM t QUALIFY row_number() OVER (PARTITION BY u ORDER BY ts DESC) = 1`)
+	stmts, err := parser.ParseStatements()
+	require.NoError(t, err)
+	require.Equal(t, 1, len(stmts))
+
+	var got []string
+	visitor := DefaultASTVisitor{Visit: func(expr Expr) error {
+		if qualify, ok := expr.(*QualifyExpr); ok {
+			got = append(got, qualify.String(0))
+		}
+		return nil
+	}}
+	err = stmts[0].Accept(&visitor)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	require.Equal(t, "QUALIFY", got[0][:len("QUALIFY")])
+	require.Equal(t, Pos(len("SELECT u FROM t ")), stmts[0].(*SelectQuery).Qualify.Pos())
+}